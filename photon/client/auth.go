@@ -0,0 +1,153 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/vmware/photon-controller-go-sdk/photon"
+	"github.com/vmware/photon-controller-go-sdk/photon/lightwave"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+// The SDK connects to the authentication server with a transport of its own, which has
+// neither the proxy nor the client certificate of the target. Tokens and certificates are
+// requested here instead, with the same requests as the SDK.
+
+const tokenPath = "/openidconnect/token"
+const tokenScope = "openid offline_access rs_esxcloud at_groups"
+const authCertsPath = "/afd/vecs/ssl"
+
+// Gets tokens from the authentication server of the target with a username and password
+func GetTokensByPassword(esxclient *photon.Client, username string, password string) (*photon.TokenOptions, error) {
+	return getTokens(esxclient, url.Values{
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+		"scope":      {tokenScope},
+	})
+}
+
+// Gets new tokens from the authentication server of the target with a refresh token
+func GetTokensByRefreshToken(esxclient *photon.Client, refreshToken string) (*photon.TokenOptions, error) {
+	return getTokens(esxclient, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+func getTokens(esxclient *photon.Client, grant url.Values) (*photon.TokenOptions, error) {
+	endpoint, err := getAuthEndpoint(esxclient)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := getConnection(esxclient).httpClient.PostForm(endpoint+tokenPath, grant)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		return nil, getOIDCError(res)
+	}
+
+	tokens := &lightwave.OIDCTokenResponse{}
+	err = json.NewDecoder(res.Body).Decode(tokens)
+	if err != nil {
+		return nil, err
+	}
+	return &photon.TokenOptions{
+		AccessToken:  tokens.AccessToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		RefreshToken: tokens.RefreshToken,
+		IdToken:      tokens.IdToken,
+		TokenType:    tokens.TokenType,
+	}, nil
+}
+
+// Returns the URL of the authentication server of the target
+func getAuthEndpoint(esxclient *photon.Client) (string, error) {
+	authInfo, err := esxclient.Auth.Get()
+	if err != nil {
+		return "", err
+	}
+	if !authInfo.Enabled {
+		return "", photon.SdkError{Message: "Authentication not enabled on this endpoint"}
+	}
+
+	port := authInfo.Port
+	if port == 0 {
+		port = 443
+	}
+	return fmt.Sprintf("https://%s:%d", authInfo.Endpoint, port), nil
+}
+
+// Returns the error of the authentication server, or the response itself when it is not
+// an OIDC error
+func getOIDCError(res *http.Response) error {
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("Status: %v [%v]", res.Status, err)
+	}
+
+	var oidcErr lightwave.OIDCError
+	err = json.Unmarshal(body, &oidcErr)
+	if err != nil {
+		return fmt.Errorf("Status: %v, Body: %v", res.Status, string(body))
+	}
+	return oidcErr
+}
+
+// Downloads the certificates of the authentication server at host, to ask the user to
+// trust them. The server is not trusted yet, so its certificate is not verified.
+func GetAuthServerCerts(config *cf.Configuration, host string) ([]*x509.Certificate, error) {
+	transport, err := NewHTTPTransport(config, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{Transport: &loggingTransport{transport}}
+
+	res, err := httpClient.Get(fmt.Sprintf("https://%s%s", host, authCertsPath))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unexpected error retrieving auth server certs: %v %s", res.StatusCode, res.Status)
+	}
+
+	var certsData []struct {
+		Value string `json:"encoded"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&certsData)
+	if err != nil {
+		return nil, err
+	}
+
+	certs := make([]*x509.Certificate, len(certsData))
+	for i, certData := range certsData {
+		block, _ := pem.Decode([]byte(certData.Value))
+		if block == nil {
+			return nil, fmt.Errorf("Unexpected response format: %v", certsData)
+		}
+		certs[i], err = x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return certs, nil
+}
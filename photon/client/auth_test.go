@@ -0,0 +1,71 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+func TestGetTokens(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth":
+			u, _ := url.Parse(server.URL)
+			host := strings.Split(u.Host, ":")
+			fmt.Fprintf(w, `{"enabled":true,"endpoint":"%s","port":%s}`, host[0], host[1])
+		case tokenPath:
+			r.ParseForm()
+			switch {
+			case r.Form.Get("grant_type") == "password" && r.Form.Get("username") == "user@tenant" &&
+				r.Form.Get("password") == "pass&word" && r.Form.Get("scope") == tokenScope:
+				w.Write([]byte(`{"access_token":"access-token","refresh_token":"refresh-token"}`))
+			case r.Form.Get("grant_type") == "refresh_token" && r.Form.Get("refresh_token") == "refresh-token":
+				w.Write([]byte(`{"access_token":"new-token"}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_grant","error_description":"wrong credentials"}`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	esxclient, err := NewClient(&cf.Configuration{CloudTarget: server.URL, IgnoreCertificate: true})
+	if err != nil {
+		t.Fatalf("Not expecting error creating client: %s", err)
+	}
+
+	tokens, err := GetTokensByPassword(esxclient, "user@tenant", "pass&word")
+	if err != nil {
+		t.Fatalf("Not expecting error getting tokens: %s", err)
+	}
+	if tokens.AccessToken != "access-token" || tokens.RefreshToken != "refresh-token" {
+		t.Errorf("Unexpected tokens: %+v", tokens)
+	}
+
+	tokens, err = GetTokensByRefreshToken(esxclient, "refresh-token")
+	if err != nil || tokens.AccessToken != "new-token" {
+		t.Errorf("Expected a new access token, got %+v and %v", tokens, err)
+	}
+
+	_, err = GetTokensByPassword(esxclient, "user@tenant", "wrong")
+	if err == nil || !strings.Contains(err.Error(), "wrong credentials") {
+		t.Errorf("Expected the error of the authentication server, got %v", err)
+	}
+}
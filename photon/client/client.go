@@ -14,8 +14,10 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"net/url"
 	"os"
+	"sync"
 
 	"github.com/vmware/photon-controller-go-sdk/photon"
 
@@ -32,6 +34,10 @@ func NewClient(config *cf.Configuration) (*photon.Client, error) {
 	if len(config.CloudTarget) == 0 {
 		return nil, errors.New("Specify a Photon Controller endpoint by running 'target set' command")
 	}
+	// The tokens could not be read from the credential store when the config was loaded
+	if config.CredentialsError() != nil {
+		return nil, config.CredentialsError()
+	}
 
 	options := &photon.ClientOptions{
 		IgnoreCertificate: config.IgnoreCertificate,
@@ -47,7 +53,7 @@ func NewClient(config *cf.Configuration) (*photon.Client, error) {
 	u, err := url.Parse(config.CloudTarget)
 	if err == nil && u.Scheme == "https" {
 		if !config.IgnoreCertificate == true {
-			roots, err := cf.GetCertsFromLocalStore()
+			roots, err := GetRootCAs(config)
 			if err == nil {
 				options.RootCAs = roots
 			} else {
@@ -56,10 +62,67 @@ func NewClient(config *cf.Configuration) (*photon.Client, error) {
 		}
 	}
 
-	esxclient := photon.NewClient(config.CloudTarget, options, logger)
+	// The SDK does not use a proxy or client certificate by itself, so they are set in
+	// the transport of the HTTP client given to it
+	httpTransport, err := NewHTTPTransport(config, options.RootCAs, options.IgnoreCertificate)
+	if err != nil {
+		return nil, err
+	}
+	var transport http.RoundTripper = httpTransport
+	if Trace != nil {
+		transport = &traceTransport{base: transport, output: Trace, curlOptions: getCurlOptions(config)}
+	}
+
+	// With a refresh token, requests go through a transport that renews the access token
+	// when needed. Requests for tokens have no access token, and are sent as they are.
+	var refresher *tokenRefresher
+	if len(config.RefreshToken) != 0 {
+		options.TokenOptions.RefreshToken = config.RefreshToken
+		refresher = &tokenRefresher{tokens: options.TokenOptions}
+		transport = &refreshTransport{transport, refresher}
+	}
+	httpClient := &http.Client{Transport: &retryTransport{&loggingTransport{transport}, Retries}}
+
+	// At its vendored revision, the SDK has no other way to use an HTTP client than the
+	// one it gives to tests
+	esxclient := photon.NewTestClient(config.CloudTarget, options, httpClient)
+	setConnection(esxclient, &connection{httpClient, options.TokenOptions})
+	if refresher != nil {
+		refresher.getTokens = func(refreshToken string) (*photon.TokenOptions, error) {
+			return GetTokensByRefreshToken(esxclient, refreshToken)
+		}
+	}
 	return esxclient, nil
 }
 
+// HTTP client and tokens of a client created by NewClient, for the requests that the
+// CLI sends itself because the SDK has no API for them
+type connection struct {
+	httpClient *http.Client
+	tokens     *photon.TokenOptions
+}
+
+var connections = map[*photon.Client]*connection{}
+var connectionsMutex sync.Mutex
+
+func setConnection(esxclient *photon.Client, conn *connection) {
+	connectionsMutex.Lock()
+	defer connectionsMutex.Unlock()
+	connections[esxclient] = conn
+}
+
+// Returns the connection of esxclient. Clients not created by NewClient, such as the
+// ones of tests, use the default HTTP client without a token.
+func getConnection(esxclient *photon.Client) *connection {
+	connectionsMutex.Lock()
+	defer connectionsMutex.Unlock()
+	conn, ok := connections[esxclient]
+	if !ok {
+		return &connection{http.DefaultClient, &photon.TokenOptions{}}
+	}
+	return conn
+}
+
 // Returns the photon client, if not set, it will read a config file.
 func GetClient(isScripting bool) (*photon.Client, error) {
 	if Esxclient == nil {
@@ -77,14 +140,15 @@ func GetClient(isScripting bool) (*photon.Client, error) {
 	return Esxclient, nil
 }
 
-// Read from local config file and create a new photon client using target
+// Read from local config file and create a new photon client using target,
+// unless the target or token are overridden
 func get() (*photon.Client, error) {
 	config, err := cf.LoadConfig()
 	if err != nil {
 		return nil, err
 	}
 
-	return NewClient(config)
+	return NewClient(cf.ApplyOverrides(config))
 }
 
 func InitializeLogging(logFileName string) error {
@@ -52,6 +52,41 @@ func TestGet(t *testing.T) {
 	}
 }
 
+func TestGetWithOverrides(t *testing.T) {
+	configOri, err := cf.LoadConfig()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+
+	err = cf.SaveConfig(&cf.Configuration{CloudTarget: "http://localhost:9080", Token: "file-token"})
+	if err != nil {
+		t.Error("Not expecting error when saving config file")
+	}
+
+	cf.ConfigOverrides = cf.Overrides{CloudTarget: "http://override:9000", Token: "override-token"}
+	client, err := get()
+	cf.ConfigOverrides = cf.Overrides{}
+	if err != nil {
+		t.Error("Not expecting error getting client with overrides")
+	}
+	if client.Endpoint != "http://override:9000" {
+		t.Errorf("Expected overridden endpoint, got %s", client.Endpoint)
+	}
+
+	config, err := cf.LoadConfig()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+	if config.CloudTarget != "http://localhost:9080" || config.Token != "file-token" {
+		t.Error("Overrides should not change the config file")
+	}
+
+	err = cf.SaveConfig(configOri)
+	if err != nil {
+		t.Error("Not expecting error when saving config file")
+	}
+}
+
 func TestLoggingFunctions(t *testing.T) {
 	defer loggingTestCleanup(test_log_file, t)
 	err := InitializeLogging(test_log_file)
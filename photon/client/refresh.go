@@ -0,0 +1,141 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware/photon-controller-go-sdk/photon"
+	"github.com/vmware/photon-controller-go-sdk/photon/lightwave"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+// Tokens expiring within this margin are refreshed before sending a request
+const tokenExpiryMargin = 30 * time.Second
+
+// Renews the access token with the refresh token saved by 'target login'.
+// The SDK client reads the access token from tokens for each request, so updating
+// it in place is enough for the following requests to use the new token.
+type tokenRefresher struct {
+	mutex     sync.Mutex
+	tokens    *photon.TokenOptions
+	getTokens func(refreshToken string) (*photon.TokenOptions, error)
+}
+
+// Tells if the access token is a JWT that has expired or is about to
+func (refresher *tokenRefresher) isExpired() bool {
+	refresher.mutex.Lock()
+	defer refresher.mutex.Unlock()
+
+	jwtToken := lightwave.ParseTokenDetails(refresher.tokens.AccessToken)
+	if jwtToken.Expires == 0 {
+		return false
+	}
+	return time.Unix(jwtToken.Expires, 0).Before(time.Now().Add(tokenExpiryMargin))
+}
+
+// Gets new tokens unless the access token already changed since failedToken was
+// used, and saves them in the config file. Returns the access token to use.
+func (refresher *tokenRefresher) refresh(failedToken string) (string, error) {
+	refresher.mutex.Lock()
+	defer refresher.mutex.Unlock()
+
+	if refresher.tokens.AccessToken != failedToken {
+		return refresher.tokens.AccessToken, nil
+	}
+
+	tokens, err := refresher.getTokens(refresher.tokens.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("Could not refresh the login token, please login again: %s", err)
+	}
+	refresher.tokens.AccessToken = tokens.AccessToken
+	if len(tokens.RefreshToken) != 0 {
+		refresher.tokens.RefreshToken = tokens.RefreshToken
+	}
+	if logger != nil {
+		logger.Printf("Refreshed the login token")
+	}
+
+	err = cf.UpdateConfig(func(config *cf.Configuration) error {
+		config.Token = refresher.tokens.AccessToken
+		config.RefreshToken = refresher.tokens.RefreshToken
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return refresher.tokens.AccessToken, nil
+}
+
+// Transport that refreshes the access token when it has expired, or when the API
+// rejects it, and then retries the request once with the new token
+type refreshTransport struct {
+	base      http.RoundTripper
+	refresher *tokenRefresher
+}
+
+const bearerPrefix = "Bearer "
+
+func (t *refreshTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	authorization := req.Header.Get("Authorization")
+	if len(authorization) == 0 {
+		return t.base.RoundTrip(req)
+	}
+	token := strings.TrimPrefix(authorization, bearerPrefix)
+
+	if t.refresher.isExpired() {
+		newToken, err := t.refresher.refresh(token)
+		if err != nil {
+			return nil, err
+		}
+		req = withToken(req, newToken)
+		token = newToken
+	}
+
+	res, err := t.base.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// The body was already sent and cannot be sent again
+		return res, err
+	}
+
+	newToken, err := t.refresher.refresh(token)
+	if err != nil {
+		if logger != nil {
+			logger.Printf("%s", err)
+		}
+		return res, nil
+	}
+	res.Body.Close()
+
+	retry := withToken(req, newToken)
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t.base.RoundTrip(retry)
+}
+
+// Returns a copy of the request using another access token
+func withToken(req *http.Request, token string) *http.Request {
+	newReq := req.Clone(req.Context())
+	newReq.Header.Set("Authorization", bearerPrefix+token)
+	return newReq
+}
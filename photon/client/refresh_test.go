@@ -0,0 +1,108 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vmware/photon-controller-go-sdk/photon"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+// Creates an unsigned JWT expiring at the given time
+func makeTestJWT(expires int64) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"none"}`)) + "." + encode([]byte(fmt.Sprintf(`{"exp":%d}`, expires))) + ".signature"
+}
+
+func testRefreshTransport(t *testing.T, accessToken string, body string) (int, int, *cf.Configuration) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	refreshCount := 0
+	refresher := &tokenRefresher{
+		tokens: &photon.TokenOptions{AccessToken: accessToken, RefreshToken: "refresh-token"},
+		getTokens: func(refreshToken string) (*photon.TokenOptions, error) {
+			refreshCount++
+			if refreshToken != "refresh-token" {
+				t.Errorf("Unexpected refresh token: %s", refreshToken)
+			}
+			return &photon.TokenOptions{AccessToken: "new-token", RefreshToken: "new-refresh-token"}, nil
+		},
+	}
+	httpClient := &http.Client{Transport: &refreshTransport{http.DefaultTransport, refresher}}
+
+	req, err := http.NewRequest("POST", server.URL, strings.NewReader(body))
+	if err != nil {
+		t.Error(err)
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+	res, err := httpClient.Do(req)
+	if err != nil {
+		t.Errorf("Not expecting error sending request: %s", err)
+		return 0, refreshCount, nil
+	}
+	res.Body.Close()
+
+	config, err := cf.LoadConfig()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+	return res.StatusCode, refreshCount, config
+}
+
+func TestRefreshTokenOnUnauthorized(t *testing.T) {
+	configOri, err := cf.LoadConfig()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+
+	status, refreshCount, config := testRefreshTransport(t, "old-token", "{}")
+	if status != http.StatusOK || refreshCount != 1 {
+		t.Errorf("Expected request to be retried once after refreshing, got status %d and %d refreshes", status, refreshCount)
+	}
+	if config == nil || config.Token != "new-token" || config.RefreshToken != "new-refresh-token" {
+		t.Error("Expected refreshed tokens to be saved in config file")
+	}
+
+	err = cf.SaveConfig(configOri)
+	if err != nil {
+		t.Error("Not expecting error when saving config file")
+	}
+}
+
+func TestRefreshExpiredToken(t *testing.T) {
+	configOri, err := cf.LoadConfig()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+
+	status, refreshCount, _ := testRefreshTransport(t, makeTestJWT(1), "")
+	if status != http.StatusOK || refreshCount != 1 {
+		t.Errorf("Expected expired token to be refreshed before the request, got status %d and %d refreshes", status, refreshCount)
+	}
+
+	err = cf.SaveConfig(configOri)
+	if err != nil {
+		t.Error("Not expecting error when saving config file")
+	}
+}
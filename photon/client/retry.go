@@ -0,0 +1,135 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// How many times, and for how long in total, idempotent requests are retried when
+// the API is unavailable, e.g. while the management plane restarts during an upgrade
+type RetryOptions struct {
+	MaxRetries int
+	MaxWait    time.Duration
+}
+
+const (
+	DefaultRetries      = 3
+	DefaultRetryMaxWait = 30 * time.Second
+)
+
+// Set from the global -retries and -retry-max-wait flags
+var Retries = RetryOptions{MaxRetries: DefaultRetries, MaxWait: DefaultRetryMaxWait}
+
+// Delay before the first retry, doubled for each following one
+var retryBaseDelay = 500 * time.Millisecond
+
+// Transport that retries GET requests, which include task polls, when the connection
+// fails or the API answers that it is unavailable. Waits between attempts grow
+// exponentially with jitter, or follow the Retry-After header of the response.
+type retryTransport struct {
+	base    http.RoundTripper
+	options RetryOptions
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	waited := time.Duration(0)
+	for attempt := 0; ; attempt++ {
+		res, err := t.base.RoundTrip(req)
+		if attempt >= t.options.MaxRetries || !isRetryable(res, err) {
+			return res, err
+		}
+
+		delay := retryDelay(res, attempt)
+		if waited+delay > t.options.MaxWait {
+			return res, err
+		}
+		if res != nil {
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		if logger != nil {
+			logger.Printf("Retrying %s %s in %s (retry %d of %d)", req.Method, req.URL, delay, attempt+1,
+				t.options.MaxRetries)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		waited += delay
+	}
+}
+
+// Tells if a request can be sent again without side effects
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS":
+		return req.Body == nil || req.Body == http.NoBody
+	}
+	return false
+}
+
+// Tells if a request failed in a way that is likely to go away: a connection error,
+// or a status telling that the API is unavailable or overloaded
+func isRetryable(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Returns how long to wait before retrying: the Retry-After delay of the response if any,
+// or else an exponential backoff with jitter
+func retryDelay(res *http.Response, attempt int) time.Duration {
+	if res != nil {
+		if delay, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return delay
+		}
+	}
+	backoff := retryBaseDelay << uint(attempt)
+	// Spread the retries of concurrent clients between half and all of the backoff
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// Parses a Retry-After header, given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(time.Now())
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
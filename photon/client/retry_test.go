@@ -0,0 +1,99 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Sends a request through a retrying transport to a server that is unavailable for
+// the given number of requests, and returns the status and number of requests received
+func testRetryTransport(t *testing.T, method string, failures int, options RetryOptions) (int, int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var req *http.Request
+	var err error
+	if method == "GET" {
+		req, err = http.NewRequest(method, server.URL, nil)
+	} else {
+		req, err = http.NewRequest(method, server.URL, strings.NewReader("{}"))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpClient := &http.Client{Transport: &retryTransport{http.DefaultTransport, options}}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatalf("Not expecting error sending request: %s", err)
+	}
+	res.Body.Close()
+	return res.StatusCode, requests
+}
+
+func TestRetryTransport(t *testing.T) {
+	baseDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = baseDelay }()
+
+	status, requests := testRetryTransport(t, "GET", 2, RetryOptions{MaxRetries: 3, MaxWait: time.Second})
+	if status != http.StatusOK || requests != 3 {
+		t.Errorf("Expected GET to succeed after 2 retries, got status %d after %d requests", status, requests)
+	}
+
+	status, requests = testRetryTransport(t, "GET", 5, RetryOptions{MaxRetries: 3, MaxWait: time.Second})
+	if status != http.StatusServiceUnavailable || requests != 4 {
+		t.Errorf("Expected GET to give up after 3 retries, got status %d after %d requests", status, requests)
+	}
+
+	status, requests = testRetryTransport(t, "GET", 2, RetryOptions{MaxRetries: 3, MaxWait: 0})
+	if status != http.StatusServiceUnavailable || requests != 1 {
+		t.Errorf("Expected no retry without wait budget, got status %d after %d requests", status, requests)
+	}
+
+	status, requests = testRetryTransport(t, "POST", 2, RetryOptions{MaxRetries: 3, MaxWait: time.Second})
+	if status != http.StatusServiceUnavailable || requests != 1 {
+		t.Errorf("Expected POST not to be retried, got status %d after %d requests", status, requests)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	res := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	res.Header.Set("Retry-After", "7")
+	if delay := retryDelay(res, 0); delay != 7*time.Second {
+		t.Errorf("Expected the Retry-After delay, got %s", delay)
+	}
+
+	res.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	if delay := retryDelay(res, 0); delay != 0 {
+		t.Errorf("Expected no delay for a Retry-After date in the past, got %s", delay)
+	}
+
+	res.Header.Del("Retry-After")
+	for attempt := 0; attempt < 4; attempt++ {
+		backoff := retryBaseDelay << uint(attempt)
+		delay := retryDelay(res, attempt)
+		if delay < backoff/2 || delay > backoff {
+			t.Errorf("Expected a delay between %s and %s for retry %d, got %s", backoff/2, backoff, attempt+1, delay)
+		}
+	}
+}
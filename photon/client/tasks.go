@@ -0,0 +1,98 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/photon-controller-go-sdk/photon"
+)
+
+// Gets the tasks matching options, asking the API for pages of pageSize tasks, or of its
+// default size when pageSize is 0. The SDK has no option for the page size, so the
+// request is built here when one is given.
+func GetTasks(esxclient *photon.Client, options *photon.TaskGetOptions, pageSize int) (*photon.TaskList, error) {
+	if pageSize == 0 {
+		return esxclient.Tasks.GetAll(options)
+	}
+
+	// The parameters are in the order the SDK gives them
+	params := []string{}
+	for _, param := range []struct{ name, value string }{
+		{"state", options.State},
+		{"kind", options.Kind},
+		{"entityId", options.EntityID},
+		{"entityKind", options.EntityKind},
+	} {
+		if len(param.value) != 0 {
+			params = append(params, param.name+"="+url.QueryEscape(param.value))
+		}
+	}
+	params = append(params, "pageSize="+url.QueryEscape(strconv.Itoa(pageSize)))
+
+	conn := getConnection(esxclient)
+	taskList := &photon.TaskList{}
+	uri := esxclient.Endpoint + "/tasks?" + strings.Join(params, "&")
+	for len(uri) != 0 {
+		page := &struct {
+			Items        []photon.Task `json:"items"`
+			NextPageLink string        `json:"nextPageLink"`
+		}{}
+		err := getJSON(conn, uri, page)
+		if err != nil {
+			return nil, err
+		}
+		taskList.Items = append(taskList.Items, page.Items...)
+
+		// The next pages keep the size of the first one
+		uri = ""
+		if len(page.NextPageLink) != 0 {
+			uri = esxclient.Endpoint + page.NextPageLink
+		}
+	}
+	return taskList, nil
+}
+
+// Gets a resource of the API with the access token of the connection, and decodes it
+// into result. Errors of the API are returned as the SDK returns them.
+func getJSON(conn *connection, uri string, result interface{}) error {
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return err
+	}
+	if len(conn.tokens.AccessToken) != 0 {
+		req.Header.Add("Authorization", bearerPrefix+conn.tokens.AccessToken)
+	}
+	res, err := conn.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		var apiError photon.ApiError
+		err = json.Unmarshal(body, &apiError)
+		if err != nil {
+			return photon.HttpError{StatusCode: res.StatusCode, Message: string(body)}
+		}
+		apiError.HttpStatusCode = res.StatusCode
+		return apiError
+	}
+	return json.NewDecoder(res.Body).Decode(result)
+}
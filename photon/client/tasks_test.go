@@ -0,0 +1,60 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vmware/photon-controller-go-sdk/photon"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+func TestGetTasksWithPageSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.RequestURI() {
+		case "/tasks?state=ERROR&entityKind=vm&pageSize=1":
+			w.Write([]byte(`{"items":[{"id":"task-1"}],"nextPageLink":"/tasks?pageLink=page-2"}`))
+		case "/tasks?pageLink=page-2":
+			w.Write([]byte(`{"items":[{"id":"task-2"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"NotFound","message":"unexpected request"}`))
+		}
+	}))
+	defer server.Close()
+
+	esxclient, err := NewClient(&cf.Configuration{CloudTarget: server.URL, Token: "access-token"})
+	if err != nil {
+		t.Fatalf("Not expecting error creating client: %s", err)
+	}
+
+	options := &photon.TaskGetOptions{State: "ERROR", EntityKind: "vm"}
+	taskList, err := GetTasks(esxclient, options, 1)
+	if err != nil {
+		t.Fatalf("Not expecting error getting tasks: %s", err)
+	}
+	if len(taskList.Items) != 2 || taskList.Items[0].ID != "task-1" || taskList.Items[1].ID != "task-2" {
+		t.Errorf("Expected the tasks of both pages, got %+v", taskList.Items)
+	}
+
+	options.EntityKind = "disk"
+	_, err = GetTasks(esxclient, options, 1)
+	apiError, ok := err.(photon.ApiError)
+	if !ok || apiError.HttpStatusCode != http.StatusNotFound {
+		t.Errorf("Expected the error of the API, got %v", err)
+	}
+}
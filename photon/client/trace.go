@@ -0,0 +1,248 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+// Where the global -trace flag writes the HTTP requests and responses, nil when not tracing
+var Trace io.Writer
+
+const redacted = "<redacted>"
+
+// Bodies longer than this are cut in traces
+const maxTraceBodyLength = 64 * 1024
+
+// Headers whose values are secrets
+var secretHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// Parts of JSON field names telling that their values are secrets
+var secretFieldNames = []string{"password", "token", "secret"}
+
+// Transport that writes each request and its response to the trace output, with the
+// secrets redacted, along with a curl command sending the same request
+type traceTransport struct {
+	base   http.RoundTripper
+	output io.Writer
+	// curl options giving the connection settings of the target
+	curlOptions []string
+	mutex       sync.Mutex
+}
+
+// Returns the curl options for the proxy, CA file and client certificate of the target
+func getCurlOptions(config *cf.Configuration) []string {
+	options := []string{}
+	if config.IgnoreCertificate {
+		options = append(options, "-k")
+	}
+	if len(config.Proxy) != 0 {
+		options = append(options, "--proxy", shellQuote(config.Proxy))
+	}
+	if len(config.CAFile) != 0 {
+		options = append(options, "--cacert", shellQuote(config.CAFile))
+	}
+	if len(config.ClientCertFile) != 0 {
+		options = append(options, "--cert", shellQuote(config.ClientCertFile))
+	}
+	if len(config.ClientKeyFile) != 0 {
+		options = append(options, "--key", shellQuote(config.ClientKeyFile))
+	}
+	return options
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		// The body read to show it is replaced in a copy of the request
+		req = req.Clone(req.Context())
+	}
+	reqBody, err := readTraceBody(&req.Body, req.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	res, err := t.base.RoundTrip(req)
+	latency := time.Since(start)
+
+	var resBody string
+	if err == nil {
+		resBody, err = readTraceBody(&res.Body, res.Header.Get("Content-Type"))
+		if err != nil {
+			res = nil
+		}
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "> %s %s\n", req.Method, req.URL)
+	writeTraceHeaders(&buf, "> ", req.Header)
+	if len(reqBody) != 0 {
+		fmt.Fprintf(&buf, "> %s\n", reqBody)
+	} else if req.Body != nil && req.Body != http.NoBody {
+		fmt.Fprintf(&buf, "> [%s body not shown]\n", req.Header.Get("Content-Type"))
+	}
+	fmt.Fprintf(&buf, "> %s\n", t.curlCommand(req, reqBody))
+	if err != nil {
+		fmt.Fprintf(&buf, "< error after %s: %s\n\n", latency, err)
+		t.output.Write(buf.Bytes())
+		return res, err
+	}
+	fmt.Fprintf(&buf, "< %s in %s\n", res.Status, latency)
+	writeTraceHeaders(&buf, "< ", res.Header)
+	if len(resBody) != 0 {
+		fmt.Fprintf(&buf, "< %s\n", resBody)
+	} else if res.ContentLength != 0 && !isTracedContentType(res.Header.Get("Content-Type")) {
+		fmt.Fprintf(&buf, "< [%s body not shown]\n", res.Header.Get("Content-Type"))
+	}
+	buf.WriteString("\n")
+	t.output.Write(buf.Bytes())
+	return res, nil
+}
+
+// Returns a curl command sending the request. The access token is read from the
+// PHOTON_TOKEN environment variable instead of being shown.
+func (t *traceTransport) curlCommand(req *http.Request, body string) string {
+	args := []string{"curl"}
+	args = append(args, t.curlOptions...)
+	args = append(args, "-X", req.Method)
+	for _, name := range sortedHeaderNames(req.Header) {
+		for _, value := range req.Header[name] {
+			if name == "Authorization" && strings.HasPrefix(value, bearerPrefix) {
+				args = append(args, "-H", `"Authorization: Bearer $PHOTON_TOKEN"`)
+			} else if secretHeaders[name] {
+				args = append(args, "-H", shellQuote(name+": "+redacted))
+			} else {
+				args = append(args, "-H", shellQuote(name+": "+value))
+			}
+		}
+	}
+	if len(body) != 0 {
+		args = append(args, "-d", shellQuote(body))
+	}
+	args = append(args, shellQuote(req.URL.String()))
+	return strings.Join(args, " ")
+}
+
+// Reads a JSON or text body to show it, with its secret fields redacted, and replaces
+// it with a copy of what was read. Other bodies, e.g. image uploads, are not read.
+func readTraceBody(body *io.ReadCloser, contentType string) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+	if !isTracedContentType(contentType) {
+		return "", nil
+	}
+
+	data, err := ioutil.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return "", err
+	}
+	*body = ioutil.NopCloser(bytes.NewReader(data))
+
+	text := redactJSON(data)
+	if len(text) > maxTraceBodyLength {
+		text = text[:maxTraceBodyLength] + fmt.Sprintf("... [%d bytes]", len(data))
+	}
+	return text, nil
+}
+
+// Tells if bodies of a content type are shown in traces
+func isTracedContentType(contentType string) bool {
+	return strings.Contains(contentType, "json") || strings.HasPrefix(contentType, "text/")
+}
+
+// Returns a JSON document with the values of its secret fields redacted, or the
+// data itself if it is not JSON
+func redactJSON(data []byte) string {
+	var document interface{}
+	if json.Unmarshal(data, &document) != nil {
+		return string(data)
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(redactSecretFields(document))
+	if err != nil {
+		return string(data)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func redactSecretFields(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, field := range typed {
+			if isSecretFieldName(key) {
+				typed[key] = redacted
+			} else {
+				typed[key] = redactSecretFields(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = redactSecretFields(item)
+		}
+	}
+	return value
+}
+
+func isSecretFieldName(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range secretFieldNames {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeTraceHeaders(w io.Writer, prefix string, header http.Header) {
+	for _, name := range sortedHeaderNames(header) {
+		for _, value := range header[name] {
+			if secretHeaders[name] {
+				value = redacted
+			}
+			fmt.Fprintf(w, "%s%s: %s\n", prefix, name, value)
+		}
+	}
+}
+
+func sortedHeaderNames(header http.Header) []string {
+	names := []string{}
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Quotes a string for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
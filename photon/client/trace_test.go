@@ -0,0 +1,83 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+func TestTraceTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"name":"vm-1","password":"secret-password"}` {
+			t.Errorf("Expected the request body to reach the server, got %s", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"task-id","refreshToken":"secret-token"}`))
+	}))
+	defer server.Close()
+
+	var trace bytes.Buffer
+	curlOptions := getCurlOptions(&cf.Configuration{IgnoreCertificate: true, Proxy: "http://proxy:3128"})
+	httpClient := &http.Client{
+		Transport: &traceTransport{base: http.DefaultTransport, output: &trace, curlOptions: curlOptions},
+	}
+	req, err := http.NewRequest("POST", server.URL+"/vms",
+		strings.NewReader(`{"name":"vm-1","password":"secret-password"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret-access-token")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		t.Fatalf("Not expecting error sending request: %s", err)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != `{"id":"task-id","refreshToken":"secret-token"}` {
+		t.Errorf("Expected the response body to reach the client, got %s", body)
+	}
+
+	output := trace.String()
+	if strings.Contains(output, "secret") {
+		t.Errorf("Expected the secrets to be redacted, got %s", output)
+	}
+	expected := []string{
+		"> POST " + server.URL + "/vms\n",
+		"> Authorization: <redacted>\n",
+		`> {"name":"vm-1","password":"<redacted>"}`,
+		`> curl -k --proxy 'http://proxy:3128' -X POST "Authorization: Bearer $PHOTON_TOKEN"`,
+		"< 201 Created in ",
+		`< {"id":"task-id","refreshToken":"<redacted>"}`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(strings.Replace(output, "-H ", "", -1), e) {
+			t.Errorf("Expected '%s' in the trace, got %s", e, output)
+		}
+	}
+	if !strings.Contains(output, "-H 'Content-Type: application/json' -d '{\"name\":\"vm-1\"") {
+		t.Errorf("Expected the body in the curl command, got %s", output)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	if quoted := shellQuote("it's"); quoted != `'it'\''s'` {
+		t.Errorf("Unexpected quoting: %s", quoted)
+	}
+}
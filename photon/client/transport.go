@@ -0,0 +1,115 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+// Creates a transport with the proxy and client certificate of the target, trusting the
+// given certificate authorities, or any certificate when insecure. It is used for every
+// connection to the target and to its authentication server.
+func NewHTTPTransport(config *cf.Configuration, rootCAs *x509.CertPool, insecure bool) (*http.Transport, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecure,
+		RootCAs:            rootCAs,
+	}
+
+	var err error
+	tlsConfig.Certificates, err = GetClientCertificates(config)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if len(config.Proxy) != 0 {
+		proxyURL, err := url.Parse(config.Proxy)
+		if err != nil || len(proxyURL.Host) == 0 {
+			return nil, fmt.Errorf("Invalid proxy '%s', should be a URL such as http://proxy:3128", config.Proxy)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	// Keep the timeouts and connection reuse of the default transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// Returns the client certificate presented to the target, if any
+func GetClientCertificates(config *cf.Configuration) ([]tls.Certificate, error) {
+	if len(config.ClientCertFile) == 0 {
+		return nil, nil
+	}
+	keyFile := config.ClientKeyFile
+	if len(keyFile) == 0 {
+		keyFile = config.ClientCertFile
+	}
+	cert, err := tls.LoadX509KeyPair(config.ClientCertFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot load client certificate '%s': %v", config.ClientCertFile, err)
+	}
+	return []tls.Certificate{cert}, nil
+}
+
+// Returns the certificate authorities trusted for the target: the certificates trusted
+// with target set or cert import and, with a CA file, the ones in it and the ones of the
+// system. Without a CA file, only the trusted certificates are used.
+func GetRootCAs(config *cf.Configuration) (*x509.CertPool, error) {
+	roots := x509.NewCertPool()
+	if len(config.CAFile) != 0 {
+		systemRoots, err := x509.SystemCertPool()
+		if err == nil {
+			roots = systemRoots
+		}
+		data, err := ioutil.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read CA file: %v", err)
+		}
+		if !roots.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("No PEM certificate found in CA file '%s'", config.CAFile)
+		}
+	}
+
+	certs, err := cf.ListCertsInLocalStore()
+	if err != nil {
+		return nil, err
+	}
+	for _, cert := range certs {
+		roots.AddCert(cert)
+	}
+	return roots, nil
+}
+
+// Transport that logs requests to the log file like the SDK does with a logger, for
+// the requests to the API and to the authentication server alike
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if logger != nil {
+		if err != nil {
+			logger.Printf("An error occured when calling %s on %s. Error: %s", req.Method, req.URL, err)
+		} else {
+			logger.Printf("[%s] %s - %s %s", res.Header.Get("request-id"), res.Status, req.Method, req.URL)
+		}
+	}
+	return res, err
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+// Writes a self signed client certificate and its key to PEM files
+func writeTestClientCert(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "photon-cli-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := path.Join(dir, "client.pem")
+	keyFile := path.Join(dir, "client-key.pem")
+	err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, certFile, keyFile
+}
+
+func TestClientCertificateAndCAFile(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "transport-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(configDir)
+	defer func(previousDir string) { cf.UserConfigDir = previousDir }(cf.UserConfigDir)
+	cf.UserConfigDir = configDir
+
+	clientCert, certFile, keyFile := writeTestClientCert(t, configDir)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"READY"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := path.Join(configDir, "ca.pem")
+	serverCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	err = ioutil.WriteFile(caFile, serverCert, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &cf.Configuration{CloudTarget: server.URL, CAFile: caFile}
+	esxclient, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("Not expecting error creating client: %s", err)
+	}
+	_, err = esxclient.Status.Get()
+	if err == nil {
+		t.Error("Expected error without a client certificate")
+	}
+
+	config.ClientCertFile = certFile
+	config.ClientKeyFile = keyFile
+	esxclient, err = NewClient(config)
+	if err != nil {
+		t.Fatalf("Not expecting error creating client: %s", err)
+	}
+	status, err := esxclient.Status.Get()
+	if err != nil || status.Status != "READY" {
+		t.Errorf("Expected the status through mutual TLS, got %v", err)
+	}
+
+	config.CAFile = path.Join(configDir, "missing.pem")
+	_, err = NewClient(config)
+	if err == nil {
+		t.Error("Expected error with a missing CA file")
+	}
+}
+
+func TestProxy(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "transport-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(configDir)
+	defer func(previousDir string) { cf.UserConfigDir = previousDir }(cf.UserConfigDir)
+	cf.UserConfigDir = configDir
+
+	requestedURL := ""
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "CONNECT":
+			// Tunnel to the authentication server, refused here
+			requestedURL = "CONNECT " + r.Host
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path == "/auth":
+			w.Write([]byte(`{"enabled":true,"endpoint":"lightwave.example.com","port":443}`))
+		default:
+			requestedURL = r.URL.String()
+			w.Write([]byte(`{"status":"READY"}`))
+		}
+	}))
+	defer proxy.Close()
+
+	esxclient, err := NewClient(&cf.Configuration{CloudTarget: "http://photon.example.com:9000", Proxy: proxy.URL})
+	if err != nil {
+		t.Fatalf("Not expecting error creating client: %s", err)
+	}
+	_, err = esxclient.Status.Get()
+	if err != nil {
+		t.Errorf("Not expecting error getting the status through the proxy: %s", err)
+	}
+	if requestedURL != "http://photon.example.com:9000/status" {
+		t.Errorf("Expected the proxy to receive the request, got '%s'", requestedURL)
+	}
+
+	// Logging in goes through the proxy too
+	_, err = GetTokensByPassword(esxclient, "user", "password")
+	if err == nil || requestedURL != "CONNECT lightwave.example.com:443" {
+		t.Errorf("Expected the proxy to receive the login request, got '%s' and %v", requestedURL, err)
+	}
+
+	_, err = NewClient(&cf.Configuration{CloudTarget: "http://photon.example.com:9000", Proxy: "proxy:3128"})
+	if err == nil {
+		t.Error("Expected error with a proxy that is not a URL")
+	}
+}
+
+func TestGetRootCAsWithoutCAFile(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "transport-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(configDir)
+	defer func(previousDir string) { cf.UserConfigDir = previousDir }(cf.UserConfigDir)
+	cf.UserConfigDir = configDir
+
+	// Only the certificates trusted with target set or cert import, and there are none
+	roots, err := GetRootCAs(&cf.Configuration{CloudTarget: "https://photon.example.com"})
+	if err != nil {
+		t.Fatalf("Not expecting error getting the root CAs: %s", err)
+	}
+	if len(roots.Subjects()) != 0 {
+		t.Errorf("Expected no certificate authority without a CA file, got %d", len(roots.Subjects()))
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/vmware/photon-controller-go-sdk/photon"
+	"github.com/vmware/photon-controller-go-sdk/photon/lightwave"
+
+	"github.com/vmware/photon-controller-cli/photon/client"
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+// Parts of flag names telling that their values are secrets, kept out of the audit log
+var secretFlagNames = []string{"password", "token", "passphrase", "secret"}
+
+// Records a task started by a command in the audit log, with its state when it starts,
+// or the one it ended in. Failing to write the audit log does not fail the command.
+func auditTask(c *cli.Context, taskID string, task *photon.Task, taskErr error) {
+	record := &cf.AuditRecord{
+		Timestamp: time.Now().UTC(),
+		Command:   sanitizeCommandLine(os.Args, getSecretFlags(c)),
+		TaskID:    taskID,
+		State:     getTaskState(task, taskErr),
+	}
+	if host, err := os.Hostname(); err == nil {
+		record.Host = host
+	}
+	if client.Esxclient != nil {
+		record.Target = client.Esxclient.Endpoint
+	}
+	if config, err := loadTargetConfig(); err == nil && len(config.Token) != 0 {
+		record.User = lightwave.ParseTokenDetails(config.Token).Subject
+	}
+	if task != nil {
+		record.Operation = task.Operation
+		record.EntityKind = task.Entity.Kind
+		record.EntityID = task.Entity.ID
+	}
+	if taskErr != nil {
+		record.Error = taskErr.Error()
+	}
+
+	err := cf.WriteAuditRecord(record)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not write the audit log: %s\n", err)
+	}
+}
+
+// Returns the state of a task, or the one it ended in according to the error of waiting
+// for it, or UNKNOWN when it could not be found
+func getTaskState(task *photon.Task, taskErr error) string {
+	if task != nil && len(task.State) != 0 {
+		return task.State
+	}
+	if taskErr == nil {
+		return "COMPLETED"
+	}
+	if errors.As(taskErr, new(photon.TaskError)) {
+		return "ERROR"
+	}
+	return "UNKNOWN"
+}
+
+// Returns the names, as given on the command line, of the global and command flags
+// whose values are secrets
+func getSecretFlags(c *cli.Context) map[string]bool {
+	var flags []cli.Flag
+	if c.App != nil {
+		flags = append(flags, c.App.Flags...)
+	}
+	flags = append(flags, c.Command.Flags...)
+
+	secretFlags := map[string]bool{}
+	for _, flag := range flags {
+		switch flag.(type) {
+		case cli.BoolFlag, cli.BoolTFlag:
+			continue
+		}
+		names := strings.Split(flag.GetName(), ",")
+		if !isSecretFlagName(names[0]) {
+			continue
+		}
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			secretFlags["-"+name] = true
+			secretFlags["--"+name] = true
+		}
+	}
+	return secretFlags
+}
+
+func isSecretFlagName(name string) bool {
+	for _, secret := range secretFlagNames {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the command line with the values of the secret flags redacted
+func sanitizeCommandLine(args []string, secretFlags map[string]bool) []string {
+	sanitized := make([]string, 0, len(args))
+	redactNext := false
+	for _, arg := range args {
+		if redactNext {
+			sanitized = append(sanitized, "<redacted>")
+			redactNext = false
+			continue
+		}
+		if i := strings.Index(arg, "="); i > 0 && secretFlags[arg[:i]] {
+			arg = arg[:i+1] + "<redacted>"
+		} else if secretFlags[arg] {
+			redactNext = true
+		}
+		sanitized = append(sanitized, arg)
+	}
+	return sanitized
+}
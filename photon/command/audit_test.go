@@ -0,0 +1,155 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/vmware/photon-controller-go-sdk/photon"
+
+	"github.com/vmware/photon-controller-cli/photon/client"
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+	"github.com/vmware/photon-controller-cli/photon/mocks"
+)
+
+func TestSanitizeCommandLine(t *testing.T) {
+	c := newConfigContext(t, nil, nil)
+	c.App = &cli.App{Flags: []cli.Flag{cli.StringFlag{Name: "token"}, cli.BoolFlag{Name: "non-interactive, n"}}}
+	c.Command = cli.Command{Flags: []cli.Flag{cli.StringFlag{Name: "password, p"}, cli.StringFlag{Name: "name, n"}}}
+
+	args := []string{"photon", "--token=abc", "-n", "host", "create", "-p", "secret", "--name", "host-1",
+		"-password=secret"}
+	expected := []string{"photon", "--token=<redacted>", "-n", "host", "create", "-p", "<redacted>", "--name",
+		"host-1", "-password=<redacted>"}
+	sanitized := sanitizeCommandLine(args, getSecretFlags(c))
+	if !reflect.DeepEqual(sanitized, expected) {
+		t.Errorf("Expected %v, got %v", expected, sanitized)
+	}
+}
+
+func TestAuditTask(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "audit-test-")
+	if err != nil {
+		t.Fatal("Not expecting error creating a temporary directory")
+	}
+	defer os.RemoveAll(configDir)
+	defer func(previousDir string) { cf.UserConfigDir = previousDir }(cf.UserConfigDir)
+	cf.UserConfigDir = configDir
+
+	task := &photon.Task{
+		State:     "ERROR",
+		Operation: "DELETE_VM",
+		Entity:    photon.Entity{ID: "vm-id", Kind: "vm"},
+	}
+	auditTask(newConfigContext(t, nil, nil), "task-id", task, photon.TaskError{ID: "task-id"})
+
+	logPath, err := cf.GetAuditLogPath()
+	if err != nil {
+		t.Fatal("Not expecting error getting the audit log path")
+	}
+	data, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Expected the audit log to be written: %s", err)
+	}
+	record := cf.AuditRecord{}
+	err = json.Unmarshal(data, &record)
+	if err != nil {
+		t.Fatalf("Expected a JSON record, got %s", data)
+	}
+	if record.TaskID != "task-id" || record.State != "ERROR" || record.Operation != "DELETE_VM" ||
+		record.EntityID != "vm-id" || len(record.Error) == 0 {
+		t.Errorf("Unexpected audit record %s", data)
+	}
+	if host, err := os.Hostname(); err == nil && record.Host != host {
+		t.Errorf("Expected the host %s in the audit record %s", host, data)
+	}
+}
+
+func TestGetTaskState(t *testing.T) {
+	cases := []struct {
+		task     *photon.Task
+		err      error
+		expected string
+	}{
+		{&photon.Task{State: "QUEUED"}, nil, "QUEUED"},
+		{nil, nil, "COMPLETED"},
+		{nil, photon.TaskError{ID: "task-id"}, "ERROR"},
+		{nil, fmt.Errorf("waiting: %w", photon.TaskError{ID: "task-id"}), "ERROR"},
+		{nil, photon.TaskTimeoutError{ID: "task-id"}, "UNKNOWN"},
+	}
+	for _, testCase := range cases {
+		state := getTaskState(testCase.task, testCase.err)
+		if state != testCase.expected {
+			t.Errorf("Expected %s for %v, got %s", testCase.expected, testCase.err, state)
+		}
+	}
+}
+
+func TestWaitStartedTaskAudit(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "audit-test-")
+	if err != nil {
+		t.Fatal("Not expecting error creating a temporary directory")
+	}
+	defer os.RemoveAll(configDir)
+	defer func(previousDir string) { cf.UserConfigDir = previousDir }(cf.UserConfigDir)
+	cf.UserConfigDir = configDir
+
+	started := photon.Task{ID: "task-id", State: "QUEUED", Operation: "DELETE_VM",
+		Entity: photon.Entity{ID: "vm-id", Kind: "vm"}}
+	completed := started
+	completed.State = "COMPLETED"
+	response, err := json.Marshal(completed)
+	if err != nil {
+		t.Error("Not expecting error serializing expected task")
+	}
+	server := mocks.NewTestServer()
+	mocks.RegisterResponder(
+		"GET",
+		server.URL+"/tasks/"+started.ID,
+		mocks.CreateResponder(200, string(response[:])))
+	defer server.Close()
+
+	mocks.Activate(true)
+	httpClient := &http.Client{Transport: mocks.DefaultMockTransport}
+	client.Esxclient = photon.NewTestClient(server.URL, nil, httpClient)
+
+	_, err = waitStartedTask(newConfigContext(t, []string{"-non-interactive"}, nil), &started, defaultTaskTimeout)
+	if err != nil {
+		t.Errorf("Not expecting error waiting for the task: %s", err)
+	}
+
+	logPath, err := cf.GetAuditLogPath()
+	if err != nil {
+		t.Fatal("Not expecting error getting the audit log path")
+	}
+	data, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Expected the audit log to be written: %s", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a record when the task starts and one when it ends, got %s", data)
+	}
+	for i, state := range []string{"QUEUED", "COMPLETED"} {
+		record := cf.AuditRecord{}
+		err = json.Unmarshal([]byte(lines[i]), &record)
+		if err != nil || record.TaskID != "task-id" || record.State != state {
+			t.Errorf("Expected a record of the task in state %s, got %s", state, lines[i])
+		}
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"strings"
 
 	"github.com/codegangsta/cli"
@@ -40,7 +39,7 @@ func GetAuthCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := show(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -56,7 +55,7 @@ func GetAuthCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := showLoginToken(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -80,7 +79,7 @@ func GetAuthCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getApiTokens(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -129,9 +128,13 @@ func showLoginTokenWriter(c *cli.Context, w io.Writer, config *configuration.Con
 		if err != nil {
 			return err
 		}
+		config = configuration.ApplyOverrides(config)
 	}
 
 	if config.Token == "" {
+		if config.CredentialsError() != nil {
+			return config.CredentialsError()
+		}
 		err = fmt.Errorf("No login token available")
 		return err
 	}
@@ -169,7 +172,7 @@ func getApiTokens(c *cli.Context) error {
 	}
 
 	if len(username) == 0 || len(password) == 0 {
-		return fmt.Errorf("Please provide username/password")
+		return usageError{"Please provide username/password"}
 	}
 
 	client.Esxclient, err = client.GetClient(c.GlobalIsSet("non-interactive"))
@@ -177,7 +180,7 @@ func getApiTokens(c *cli.Context) error {
 		return err
 	}
 
-	tokens, err := client.Esxclient.Auth.GetTokensByPassword(username, password)
+	tokens, err := client.GetTokensByPassword(client.Esxclient, username, password)
 	if err != nil {
 		return err
 	}
@@ -12,7 +12,6 @@ package command
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"text/tabwriter"
 
@@ -46,7 +45,7 @@ func GetAvailabilityZonesCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createAvailabilityZone(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -56,7 +55,7 @@ func GetAvailabilityZonesCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := deleteAvailabilityZone(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -66,7 +65,7 @@ func GetAvailabilityZonesCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listAvailabilityZones(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -76,7 +75,7 @@ func GetAvailabilityZonesCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := showAvailabilityZone(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -92,7 +91,7 @@ func GetAvailabilityZonesCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getAvailabilityZoneTasks(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -119,7 +118,7 @@ func createAvailabilityZone(c *cli.Context, w io.Writer) error {
 	}
 
 	if len(name) == 0 {
-		return fmt.Errorf("Please provide availability zone name")
+		return usageError{"Please provide availability zone name"}
 	}
 
 	azSpec := &photon.AvailabilityZoneCreateSpec{
@@ -136,7 +135,7 @@ func createAvailabilityZone(c *cli.Context, w io.Writer) error {
 		return err
 	}
 
-	id, err := waitOnTaskOperation(createTask.ID, c)
+	id, err := waitOnTaskOperation(createTask, c)
 	if err != nil {
 		return err
 	}
@@ -242,7 +241,7 @@ func deleteAvailabilityZone(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(deleteTask.ID, c)
+	_, err = waitOnTaskOperation(deleteTask, c)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,389 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codegangsta/cli"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+	"github.com/vmware/photon-controller-cli/photon/utils"
+)
+
+// Number of hex digits of the fingerprints shown by cert list
+const shortFingerprintLength = 16
+
+// Create a cli.command object for command "cert"
+// Subcommands: list;   Usage: cert list
+//              show;   Usage: cert show <fingerprint>
+//              remove; Usage: cert remove <fingerprint>
+//              import; Usage: cert import <file>
+//              export; Usage: cert export [<fingerprint>] [<options>]
+func GetCertsCommand() cli.Command {
+	command := cli.Command{
+		Name:  "cert",
+		Usage: "options for the certificates trusted by the current target context",
+		Subcommands: []cli.Command{
+			{
+				Name:  "list",
+				Usage: "List trusted certificates",
+				Action: func(c *cli.Context) {
+					err := listCerts(c, os.Stdout)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "show",
+				Usage: "Show the trusted certificate with the specified SHA-256 fingerprint, or the start of it",
+				Action: func(c *cli.Context) {
+					err := showCert(c, os.Stdout)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "remove",
+				Usage: "Stop trusting the certificate with the specified SHA-256 fingerprint",
+				Action: func(c *cli.Context) {
+					err := removeCert(c)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "import",
+				Usage: "Trust the certificates in a PEM or DER file, e.g. a CA bundle",
+				Action: func(c *cli.Context) {
+					err := importCerts(c, os.Stdout)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "export",
+				Usage: "Write trusted certificates in PEM format, all of them unless a fingerprint is given",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "file, f",
+						Usage: "File to write the certificates to, instead of the standard output",
+					},
+				},
+				Action: func(c *cli.Context) {
+					err := exportCerts(c, os.Stdout)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+		},
+	}
+	return command
+}
+
+// Description of a trusted certificate
+type certInfo struct {
+	Fingerprint string    `json:"fingerprint"`
+	Subject     string    `json:"subject"`
+	Issuer      string    `json:"issuer"`
+	NotBefore   time.Time `json:"notBefore"`
+	NotAfter    time.Time `json:"notAfter"`
+	IsCA        bool      `json:"isCA"`
+	DNSNames    []string  `json:"dnsNames,omitempty"`
+	IPAddresses []string  `json:"ipAddresses,omitempty"`
+}
+
+func newCertInfo(cert *x509.Certificate) certInfo {
+	info := certInfo{
+		Fingerprint: certFingerprint(cert),
+		Subject:     cert.Subject.String(),
+		Issuer:      cert.Issuer.String(),
+		NotBefore:   cert.NotBefore,
+		NotAfter:    cert.NotAfter,
+		IsCA:        cert.IsCA,
+		DNSNames:    cert.DNSNames,
+	}
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+	return info
+}
+
+// Returns the expiry date of a certificate, marked if it has passed
+func formatCertExpiry(notAfter time.Time) string {
+	expiry := notAfter.Format(time.RFC3339)
+	if time.Now().After(notAfter) {
+		expiry += " (expired)"
+	}
+	return expiry
+}
+
+// Lists the certificates trusted by the context in use
+func listCerts(c *cli.Context, w io.Writer) error {
+	err := checkArgNum(c.Args(), 0, "cert list")
+	if err != nil {
+		return err
+	}
+
+	certs, err := cf.ListCertsInLocalStore()
+	if err != nil {
+		return err
+	}
+	infoList := []certInfo{}
+	for _, cert := range certs {
+		infoList = append(infoList, newCertInfo(cert))
+	}
+
+	if c.GlobalIsSet("non-interactive") {
+		for _, info := range infoList {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", info.Fingerprint, info.Subject, info.Issuer,
+				info.NotAfter.Format(time.RFC3339))
+		}
+	} else if utils.NeedsFormatting(c) {
+		utils.FormatObjects(infoList, w, c)
+	} else {
+		tw := new(tabwriter.Writer)
+		tw.Init(w, 4, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "Fingerprint (SHA-256)\tSubject\tIssuer\tExpires\n")
+		for _, info := range infoList {
+			fingerprint := strings.TrimPrefix(info.Fingerprint, "sha256:")[:shortFingerprintLength]
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", fingerprint, info.Subject, info.Issuer, formatCertExpiry(info.NotAfter))
+		}
+		err = tw.Flush()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\nTotal: %d\n", len(infoList))
+	}
+	return nil
+}
+
+// Shows the details of a trusted certificate
+func showCert(c *cli.Context, w io.Writer) error {
+	err := checkArgNum(c.Args(), 1, "cert show <fingerprint>")
+	if err != nil {
+		return err
+	}
+
+	cert, err := findTrustedCert(c.Args().First())
+	if err != nil {
+		return err
+	}
+	info := newCertInfo(cert)
+
+	if c.GlobalIsSet("non-interactive") {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\t%s\n", info.Fingerprint, info.Subject, info.Issuer,
+			info.NotBefore.Format(time.RFC3339), info.NotAfter.Format(time.RFC3339), info.IsCA,
+			strings.Join(append(info.DNSNames, info.IPAddresses...), ","))
+	} else if utils.NeedsFormatting(c) {
+		utils.FormatObject(info, w, c)
+	} else {
+		fmt.Fprintf(w, "Fingerprint: %s\n", info.Fingerprint)
+		fmt.Fprintf(w, "  Subject:     %s\n", info.Subject)
+		fmt.Fprintf(w, "  Issuer:      %s\n", info.Issuer)
+		fmt.Fprintf(w, "  Valid from:  %s\n", info.NotBefore.Format(time.RFC3339))
+		fmt.Fprintf(w, "  Expires:     %s\n", formatCertExpiry(info.NotAfter))
+		fmt.Fprintf(w, "  CA:          %t\n", info.IsCA)
+		if len(info.DNSNames) != 0 || len(info.IPAddresses) != 0 {
+			fmt.Fprintf(w, "  Names:       %s\n", strings.Join(append(info.DNSNames, info.IPAddresses...), ", "))
+		}
+	}
+	return nil
+}
+
+// Stops trusting a certificate
+func removeCert(c *cli.Context) error {
+	err := checkArgNum(c.Args(), 1, "cert remove <fingerprint>")
+	if err != nil {
+		return err
+	}
+
+	cert, err := findTrustedCert(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsNonInteractive(c) {
+		fmt.Printf("Removing certificate %s\n  Subject: %s\n", certFingerprint(cert), cert.Subject.String())
+	}
+	if confirmed(utils.IsNonInteractive(c)) {
+		err = cf.RemoveCertFromLocalStore(cert)
+		if err != nil {
+			return err
+		}
+		if !utils.IsNonInteractive(c) {
+			fmt.Println("Certificate removed")
+		}
+	} else {
+		fmt.Println("Cancelled")
+	}
+	return nil
+}
+
+// Trusts the certificates of a PEM or DER file
+func importCerts(c *cli.Context, w io.Writer) error {
+	err := checkArgNum(c.Args(), 1, "cert import <file>")
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(c.Args().First())
+	if err != nil {
+		return err
+	}
+	certs, err := parseCerts(data)
+	if err != nil {
+		return err
+	}
+
+	infoList := []certInfo{}
+	for _, cert := range certs {
+		err = cf.AddCertToLocalStore(cert)
+		if err != nil {
+			return err
+		}
+		infoList = append(infoList, newCertInfo(cert))
+	}
+
+	if c.GlobalIsSet("non-interactive") {
+		for _, info := range infoList {
+			fmt.Fprintf(w, "%s\n", info.Fingerprint)
+		}
+	} else if utils.NeedsFormatting(c) {
+		utils.FormatObjects(infoList, w, c)
+	} else {
+		for _, info := range infoList {
+			fmt.Fprintf(w, "Trusted %s\n  Subject: %s\n", info.Fingerprint, info.Subject)
+		}
+		fmt.Fprintf(w, "\nTotal: %d\n", len(infoList))
+	}
+	return nil
+}
+
+// Writes trusted certificates in PEM format
+func exportCerts(c *cli.Context, w io.Writer) (err error) {
+	if len(c.Args()) > 1 {
+		return usageError{fmt.Sprintf("Unknown arguments: %v. Usage: cert export [<fingerprint>]", c.Args()[1:])}
+	}
+
+	var certs []*x509.Certificate
+	if len(c.Args()) == 1 {
+		cert, err := findTrustedCert(c.Args().First())
+		if err != nil {
+			return err
+		}
+		certs = []*x509.Certificate{cert}
+	} else {
+		certs, err = cf.ListCertsInLocalStore()
+		if err != nil {
+			return err
+		}
+	}
+
+	file := c.String("file")
+	if len(file) != 0 {
+		var f *os.File
+		f, err = os.Create(file)
+		if err != nil {
+			return err
+		}
+		// Sets the named result, so that an error writing the file on close is returned
+		defer func() {
+			closeErr := f.Close()
+			if err == nil {
+				err = closeErr
+			}
+		}()
+		w = f
+	}
+
+	for _, cert := range certs {
+		err = pem.Encode(w, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(file) != 0 && !utils.IsNonInteractive(c) {
+		fmt.Printf("Exported %d certificates to %s\n", len(certs), file)
+	}
+	return nil
+}
+
+// Finds a trusted certificate from its SHA-256 fingerprint, or the start of it
+func findTrustedCert(fingerprint string) (*x509.Certificate, error) {
+	fingerprint = normalizeFingerprint(fingerprint)
+	if fingerprint == "sha256:" {
+		return nil, usageError{"Please provide a certificate fingerprint"}
+	}
+
+	certs, err := cf.ListCertsInLocalStore()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *x509.Certificate
+	for _, cert := range certs {
+		if strings.HasPrefix(certFingerprint(cert), fingerprint) {
+			if found != nil {
+				return nil, fmt.Errorf("Several trusted certificates match '%s', give more of the fingerprint",
+					fingerprint)
+			}
+			found = cert
+		}
+	}
+	if found == nil {
+		return nil, notFoundError{fmt.Sprintf("No trusted certificate matches '%s'", fingerprint)}
+	}
+	return found, nil
+}
+
+// Parses the certificates of a file: PEM blocks, or else DER certificates
+func parseCerts(data []byte) ([]*x509.Certificate, error) {
+	certs := []*x509.Certificate{}
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid certificate: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) != 0 {
+		return certs, nil
+	}
+
+	certs, err := x509.ParseCertificates(data)
+	if err != nil || len(certs) == 0 {
+		return nil, fmt.Errorf("No PEM or DER certificate found")
+	}
+	return certs, nil
+}
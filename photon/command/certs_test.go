@@ -0,0 +1,114 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+func TestCertCommands(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "certs-test-")
+	if err != nil {
+		t.Fatal("Not expecting error creating a temporary directory")
+	}
+	defer os.RemoveAll(configDir)
+	defer func(previousDir string) { cf.UserConfigDir = previousDir }(cf.UserConfigDir)
+	cf.UserConfigDir = configDir
+
+	cert_b, _ := genTestRootCert()
+	cert, err := x509.ParseCertificate(cert_b)
+	if err != nil {
+		t.Fatal("Not expecting error parsing the test certificate")
+	}
+	fingerprint := certFingerprint(cert)
+
+	// Import a DER file
+	certFile := path.Join(configDir, "ca.der")
+	err = ioutil.WriteFile(certFile, cert_b, 0600)
+	if err != nil {
+		t.Fatal("Not expecting error writing the certificate")
+	}
+	var buf bytes.Buffer
+	err = importCerts(newConfigContext(t, []string{"-non-interactive"}, []string{certFile}), &buf)
+	if err != nil {
+		t.Errorf("Not expecting error importing certificate: %s", err)
+	}
+	if buf.String() != fingerprint+"\n" {
+		t.Errorf("Expected the fingerprint of the imported certificate, got %s", buf.String())
+	}
+
+	buf.Reset()
+	err = listCerts(newConfigContext(t, []string{"-output", "json"}, nil), &buf)
+	if err != nil {
+		t.Error("Not expecting error listing certificates")
+	}
+	var infoList []certInfo
+	err = json.Unmarshal(buf.Bytes(), &infoList)
+	if err != nil || len(infoList) != 1 || infoList[0].Fingerprint != fingerprint || !infoList[0].IsCA {
+		t.Errorf("Expected the imported certificate, got %s", buf.String())
+	}
+
+	// Show it from the start of its fingerprint, as printed by openssl
+	opensslFingerprint := strings.ToUpper(fingerprint[7:9] + ":" + fingerprint[9:11])
+	buf.Reset()
+	err = showCert(newConfigContext(t, nil, []string{opensslFingerprint}), &buf)
+	if err != nil {
+		t.Errorf("Not expecting error showing certificate: %s", err)
+	}
+	if !strings.Contains(buf.String(), fingerprint) || !strings.Contains(buf.String(), "O=Test") {
+		t.Errorf("Expected the certificate details, got %s", buf.String())
+	}
+
+	buf.Reset()
+	err = exportCerts(newConfigContext(t, []string{"-non-interactive"}, nil), &buf)
+	if err != nil {
+		t.Error("Not expecting error exporting certificates")
+	}
+	block, _ := pem.Decode(buf.Bytes())
+	if block == nil || !bytes.Equal(block.Bytes, cert_b) {
+		t.Errorf("Expected the certificate in PEM format, got %s", buf.String())
+	}
+
+	// Importing the exported PEM bundle again does not add a certificate
+	pemFile := path.Join(configDir, "ca.pem")
+	err = ioutil.WriteFile(pemFile, buf.Bytes(), 0600)
+	if err != nil {
+		t.Fatal("Not expecting error writing the certificate")
+	}
+	buf.Reset()
+	err = importCerts(newConfigContext(t, []string{"-non-interactive"}, []string{pemFile}), &buf)
+	if err != nil {
+		t.Errorf("Not expecting error importing certificate: %s", err)
+	}
+
+	err = removeCert(newConfigContext(t, []string{"-non-interactive"}, []string{fingerprint}))
+	if err != nil {
+		t.Errorf("Not expecting error removing certificate: %s", err)
+	}
+	certs, err := cf.ListCertsInLocalStore()
+	if err != nil || len(certs) != 0 {
+		t.Errorf("Expected no trusted certificate after remove, got %d", len(certs))
+	}
+
+	err = showCert(newConfigContext(t, nil, []string{fingerprint}), &buf)
+	if err == nil {
+		t.Error("Expected error showing a removed certificate")
+	}
+}
@@ -13,11 +13,9 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/vmware/photon-controller-cli/photon/client"
@@ -135,17 +133,23 @@ func GetClusterCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createCluster(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
 			{
 				Name:  "show",
 				Usage: "Show information about a cluster",
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "watch, w",
+						Usage: "Poll for changes and print the rows that changed, until interrupted",
+					},
+				},
 				Action: func(c *cli.Context) {
 					err := showCluster(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -165,11 +169,17 @@ func GetClusterCommand() cli.Command {
 						Name:  "summary, s",
 						Usage: "Summary view",
 					},
+					filterFlag("type=KUBERNETES,name~=^prod"),
+					sortByFlag(".workerCount"),
+					cli.BoolFlag{
+						Name:  "watch, w",
+						Usage: "Poll for changes and print the rows that changed, until interrupted",
+					},
 				},
 				Action: func(c *cli.Context) {
 					err := listClusters(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -179,7 +189,7 @@ func GetClusterCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listVms(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -195,7 +205,7 @@ func GetClusterCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := resizeCluster(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -205,7 +215,7 @@ func GetClusterCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := deleteCluster(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -279,13 +289,13 @@ func createCluster(c *cli.Context, w io.Writer) error {
 			}
 			worker_count, err = strconv.Atoi(worker_count_string)
 			if err != nil {
-				return fmt.Errorf("Please supply a valid worker count")
+				return usageError{"Please supply a valid worker count"}
 			}
 		}
 	}
 
 	if len(name) == 0 || len(cluster_type) == 0 {
-		return fmt.Errorf("Provide a valid cluster name and type")
+		return usageError{"Provide a valid cluster name and type"}
 	}
 
 	if worker_count == 0 {
@@ -312,7 +322,7 @@ func createCluster(c *cli.Context, w io.Writer) error {
 	}
 
 	if len(dns) == 0 || len(gateway) == 0 || len(netmask) == 0 {
-		return fmt.Errorf("Provide a valid DNS, gateway, and netmask")
+		return usageError{"Provide a valid DNS, gateway, and netmask"}
 	}
 
 	extended_properties := make(map[string]string)
@@ -416,7 +426,7 @@ func createCluster(c *cli.Context, w io.Writer) error {
 			}
 		}
 	default:
-		return fmt.Errorf("Unsupported cluster type: %s", cluster_type)
+		return usageError{fmt.Sprintf("Unsupported cluster type: %s", cluster_type)}
 	}
 
 	clusterSpec := photon.ClusterCreateSpec{}
@@ -451,7 +461,7 @@ func createCluster(c *cli.Context, w io.Writer) error {
 			return err
 		}
 
-		_, err = waitOnTaskOperation(createTask.ID, c)
+		_, err = waitOnTaskOperation(createTask, c)
 		if err != nil {
 			return err
 		}
@@ -497,6 +507,16 @@ func showCluster(c *cli.Context, w io.Writer) error {
 		return err
 	}
 
+	if c.Bool("watch") {
+		return watch(c, w, func() (*watchSnapshot, error) {
+			cluster, err := client.Esxclient.Clusters.Get(id)
+			if err != nil {
+				return nil, err
+			}
+			return objectWatchSnapshot(cluster.ID, cluster)
+		})
+	}
+
 	cluster, err := client.Esxclient.Clusters.Get(id)
 	if err != nil {
 		return err
@@ -548,6 +568,10 @@ func listClusters(c *cli.Context, w io.Writer) error {
 	if err != nil {
 		return err
 	}
+	err = checkFilterAndSort(c)
+	if err != nil {
+		return err
+	}
 
 	tenantName := c.String("tenant")
 	projectName := c.String("project")
@@ -568,11 +592,30 @@ func listClusters(c *cli.Context, w io.Writer) error {
 		return err
 	}
 
+	if c.Bool("watch") {
+		return watch(c, w, func() (*watchSnapshot, error) {
+			clusterList, err := client.Esxclient.Projects.GetClusters(project.ID)
+			if err != nil {
+				return nil, err
+			}
+			err = filterAndSortList(&clusterList.Items, c)
+			if err != nil {
+				return nil, err
+			}
+			return clusterListWatchSnapshot(clusterList.Items), nil
+		})
+	}
+
 	clusterList, err := client.Esxclient.Projects.GetClusters(project.ID)
 	if err != nil {
 		return err
 	}
 
+	err = filterAndSortList(&clusterList.Items, c)
+	if err != nil {
+		return err
+	}
+
 	err = printClusterList(clusterList.Items, w, c, summaryView)
 	if err != nil {
 		return err
@@ -622,7 +665,7 @@ func resizeCluster(c *cli.Context, w io.Writer) error {
 	wait_for_ready := c.IsSet("wait-for-ready")
 
 	if len(cluster_id) == 0 || err != nil || worker_count <= 0 {
-		return fmt.Errorf("Provide a valid cluster ID and worker count")
+		return usageError{"Provide a valid cluster ID and worker count"}
 	}
 
 	client.Esxclient, err = client.GetClient(utils.IsNonInteractive(c))
@@ -642,7 +685,7 @@ func resizeCluster(c *cli.Context, w io.Writer) error {
 			return err
 		}
 
-		_, err = waitOnTaskOperation(resizeTask.ID, c)
+		_, err = waitOnTaskOperation(resizeTask, c)
 		if err != nil {
 			return err
 		}
@@ -681,7 +724,7 @@ func deleteCluster(c *cli.Context) error {
 	cluster_id := c.Args().First()
 
 	if len(cluster_id) == 0 {
-		return fmt.Errorf("Please provide a valid cluster ID")
+		return usageError{"Please provide a valid cluster ID"}
 	}
 
 	client.Esxclient, err = client.GetClient(utils.IsNonInteractive(c))
@@ -699,7 +742,7 @@ func deleteCluster(c *cli.Context) error {
 			return err
 		}
 
-		_, err = waitOnTaskOperation(deleteTask.ID, c)
+		_, err = waitOnTaskOperation(deleteTask, c)
 		if err != nil {
 			return err
 		}
@@ -712,48 +755,55 @@ func deleteCluster(c *cli.Context) error {
 
 // Helper routine which waits for a cluster to enter the READY state.
 func waitForCluster(id string) (cluster *photon.Cluster, err error) {
-	start := time.Now()
+	options, err := getPollOptions(60*time.Minute, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
 	numErr := 0
+	interval := options.interval
 
-	taskPollTimeout := 60 * time.Minute
-	taskPollDelay := 2 * time.Second
-	taskRetryCount := 3
-
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		displayTaskProgress(start)
+	tracker := newTaskTracker(id)
+	renderer := newProgressRenderer(os.Stdout, []*taskTracker{tracker})
+	renderer.start()
+	interrupted, stopWatching := watchInterrupt()
+	defer func() {
+		stopWatching()
+		tracker.finish(err)
+		renderer.stop()
 	}()
 
-	for time.Since(start) < taskPollTimeout {
+	for time.Since(tracker.start) < options.timeout {
+		changed := false
 		cluster, err = client.Esxclient.Clusters.Get(id)
 		if err != nil {
 			numErr++
 			if numErr > taskRetryCount {
-				endAnimation = true
-				wg.Wait()
+				return
+			}
+		} else {
+			numErr = 0
+			changed = tracker.updateState("WAIT_FOR_CLUSTER", strings.ToUpper(cluster.State))
+			switch strings.ToUpper(cluster.State) {
+			case "ERROR":
+				err = fmt.Errorf("Cluster %s entered ERROR state", id)
+				return
+			case "READY":
 				return
 			}
 		}
-		switch strings.ToUpper(cluster.State) {
-		case "ERROR":
-			endAnimation = true
-			wg.Wait()
-			err = fmt.Errorf("Cluster %s entered ERROR state", id)
-			return
-		case "READY":
-			endAnimation = true
-			wg.Wait()
+
+		interval = nextPollInterval(interval, options, changed)
+		select {
+		case <-interrupted:
+			err = interruptedError{fmt.Sprintf("cluster %s still getting ready; check it with `photon cluster show %s`",
+				id, id)}
 			return
+		case <-time.After(interval):
 		}
-
-		time.Sleep(taskPollDelay)
 	}
 
-	endAnimation = true
-	wg.Wait()
-	err = fmt.Errorf("Timed out while waiting for cluster to enter READY state")
+	err = timeoutError{fmt.Sprintf("Timed out after %s waiting for cluster %s to enter READY state, "+
+		"check it with 'photon cluster show %s'", options.timeout, id, id)}
 	return
 }
 
@@ -0,0 +1,237 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/codegangsta/cli"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+	"github.com/vmware/photon-controller-cli/photon/utils"
+)
+
+// Create a cli.command object for command "config"
+// Subcommands: view;  Usage: config view
+//              get;   Usage: config get <key> [--show-secrets]
+//              set;   Usage: config set <key> <value>
+//              unset; Usage: config unset <key>
+//              path;  Usage: config path
+func GetConfigCommand() cli.Command {
+	command := cli.Command{
+		Name:  "config",
+		Usage: "options for the CLI configuration",
+		Subcommands: []cli.Command{
+			{
+				Name:  "view",
+				Usage: "Show all the settings of the config file, with secrets redacted",
+				Action: func(c *cli.Context) {
+					err := viewConfig(c, os.Stdout)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "get",
+				Usage: "Show a setting, e.g. CloudTarget, Tenant.Name or Contexts.<name>.CloudTarget",
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "show-secrets",
+						Usage: "show tokens in clear text instead of redacted",
+					},
+				},
+				Action: func(c *cli.Context) {
+					err := getConfigValue(c, os.Stdout)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "set",
+				Usage: "Change a setting",
+				Action: func(c *cli.Context) {
+					err := setConfigValue(c)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "unset",
+				Usage: "Reset a setting to its default value",
+				Action: func(c *cli.Context) {
+					err := unsetConfigValue(c)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "path",
+				Usage: "Show the path of the config file",
+				Action: func(c *cli.Context) {
+					err := showConfigPath(c, os.Stdout)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+		},
+	}
+	return command
+}
+
+// Shows every setting of the config file, without the secrets
+func viewConfig(c *cli.Context, w io.Writer) error {
+	err := checkArgNum(c.Args(), 0, "config view")
+	if err != nil {
+		return err
+	}
+
+	contexts, err := cf.LoadContexts()
+	if err != nil {
+		return err
+	}
+	redacted := contexts.Redacted()
+
+	if c.GlobalIsSet("non-interactive") {
+		for _, setting := range redacted.Settings() {
+			fmt.Fprintf(w, "%s\t%s\n", setting.Key, configValueToString(setting.Value))
+		}
+	} else if utils.NeedsFormatting(c) {
+		utils.FormatObject(redacted, w, c)
+	} else {
+		tw := new(tabwriter.Writer)
+		tw.Init(w, 4, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "Key\tValue\n")
+		for _, setting := range redacted.Settings() {
+			fmt.Fprintf(tw, "%s\t%s\n", setting.Key, configValueToString(setting.Value))
+		}
+		err = tw.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Shows a single setting, with the secrets redacted unless --show-secrets is given
+func getConfigValue(c *cli.Context, w io.Writer) error {
+	err := checkArgNum(c.Args(), 1, "config get <key>")
+	if err != nil {
+		return err
+	}
+
+	contexts, err := cf.LoadContexts()
+	if err != nil {
+		return err
+	}
+	if !c.Bool("show-secrets") {
+		contexts = contexts.Redacted()
+	}
+	value, err := contexts.GetValue(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if utils.NeedsFormatting(c) {
+		utils.FormatObject(value, w, c)
+	} else {
+		fmt.Fprintf(w, "%s\n", configValueToString(value))
+	}
+	return nil
+}
+
+// Changes a single setting
+func setConfigValue(c *cli.Context) error {
+	err := checkArgNum(c.Args(), 2, "config set <key> <value>")
+	if err != nil {
+		return err
+	}
+	key := c.Args()[0]
+	value := c.Args()[1]
+
+	err = cf.UpdateContexts(func(contexts *cf.ContextsConfiguration) error {
+		return contexts.SetValue(key, value)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsNonInteractive(c) {
+		fmt.Printf("'%s' set\n", key)
+	}
+	return nil
+}
+
+// Resets a single setting
+func unsetConfigValue(c *cli.Context) error {
+	err := checkArgNum(c.Args(), 1, "config unset <key>")
+	if err != nil {
+		return err
+	}
+	key := c.Args().First()
+
+	err = cf.UpdateContexts(func(contexts *cf.ContextsConfiguration) error {
+		return contexts.UnsetValue(key)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsNonInteractive(c) {
+		fmt.Printf("'%s' unset\n", key)
+	}
+	return nil
+}
+
+// Shows the path of the config file
+func showConfigPath(c *cli.Context, w io.Writer) error {
+	err := checkArgNum(c.Args(), 0, "config path")
+	if err != nil {
+		return err
+	}
+
+	path, err := cf.GetConfigFilePath()
+	if err != nil {
+		return err
+	}
+
+	if utils.NeedsFormatting(c) {
+		utils.FormatObject(map[string]string{"path": path}, w, c)
+	} else {
+		fmt.Fprintf(w, "%s\n", path)
+	}
+	return nil
+}
+
+// Converts the value of a setting to text; nested configurations are shown as JSON
+func configValueToString(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case bool, int:
+		return fmt.Sprint(typed)
+	default:
+		data, err := json.Marshal(typed)
+		if err != nil {
+			return fmt.Sprint(typed)
+		}
+		return string(data)
+	}
+}
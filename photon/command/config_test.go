@@ -0,0 +1,160 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+func newConfigContext(t *testing.T, globalArgs []string, args []string) *cli.Context {
+	globalSet := flag.NewFlagSet("test", 0)
+	globalSet.Bool("non-interactive", false, "doc")
+	globalSet.String("output", "", "doc")
+	err := globalSet.Parse(globalArgs)
+	if err != nil {
+		t.Error("Not expecting arguments parsing to fail")
+	}
+	globalCtx := cli.NewContext(nil, globalSet, nil)
+
+	set := flag.NewFlagSet("test", 0)
+	set.Bool("show-secrets", false, "doc")
+	err = set.Parse(args)
+	if err != nil {
+		t.Error("Not expecting arguments parsing to fail")
+	}
+	return cli.NewContext(nil, set, globalCtx)
+}
+
+func TestConfigCommands(t *testing.T) {
+	contextsOri, err := cf.LoadContexts()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+
+	err = cf.RemoveConfigFile()
+	if err != nil {
+		t.Error("Not expecting error removing config file")
+	}
+
+	settings := [][]string{
+		{"CloudTarget", "http://localhost:9000"},
+		{"tenant.name", "tenant-1"},
+		{"Token", "secret-token"},
+		{"IgnoreCertificate", "true"},
+	}
+	for _, setting := range settings {
+		err = setConfigValue(newConfigContext(t, []string{"-non-interactive"}, setting))
+		if err != nil {
+			t.Errorf("Not expecting error setting %s: %s", setting[0], err)
+		}
+	}
+
+	config, err := cf.LoadConfig()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+	if config.CloudTarget != "http://localhost:9000" || config.Tenant == nil || config.Tenant.Name != "tenant-1" ||
+		config.Token != "secret-token" || !config.IgnoreCertificate {
+		t.Errorf("Settings were not saved, got %+v", config)
+	}
+
+	err = setConfigValue(newConfigContext(t, []string{"-non-interactive"}, []string{"IgnoreCertificate", "maybe"}))
+	if err == nil {
+		t.Error("Expected error setting a boolean to 'maybe'")
+	}
+	err = setConfigValue(newConfigContext(t, []string{"-non-interactive"}, []string{"Version", "2"}))
+	if err == nil {
+		t.Error("Expected error setting the version")
+	}
+
+	var buf bytes.Buffer
+	err = getConfigValue(newConfigContext(t, nil, []string{"cloudtarget"}), &buf)
+	if err != nil {
+		t.Error("Not expecting error getting CloudTarget")
+	}
+	if buf.String() != "http://localhost:9000\n" {
+		t.Errorf("Unexpected CloudTarget: %s", buf.String())
+	}
+
+	buf.Reset()
+	err = getConfigValue(newConfigContext(t, []string{"-output", "json"}, []string{"Tenant"}), &buf)
+	if err != nil {
+		t.Error("Not expecting error getting Tenant")
+	}
+	var tenant cf.TenantConfiguration
+	err = json.Unmarshal(buf.Bytes(), &tenant)
+	if err != nil || tenant.Name != "tenant-1" {
+		t.Errorf("Expected the tenant as JSON, got %s", buf.String())
+	}
+
+	for _, key := range []string{"Token", "Contexts.default.Token"} {
+		buf.Reset()
+		err = getConfigValue(newConfigContext(t, nil, []string{key}), &buf)
+		if err != nil || buf.String() != cf.RedactedValue+"\n" {
+			t.Errorf("Expected %s to be redacted, got %s", key, buf.String())
+		}
+	}
+
+	buf.Reset()
+	err = getConfigValue(newConfigContext(t, []string{"-output", "json"}, []string{"Contexts.default"}), &buf)
+	if err != nil || strings.Contains(buf.String(), "secret-token") {
+		t.Errorf("Expected the token of the context to be redacted, got %s", buf.String())
+	}
+
+	buf.Reset()
+	err = getConfigValue(newConfigContext(t, nil, []string{"-show-secrets", "Token"}), &buf)
+	if err != nil || buf.String() != "secret-token\n" {
+		t.Errorf("Expected the token with --show-secrets, got %s", buf.String())
+	}
+
+	buf.Reset()
+	err = viewConfig(newConfigContext(t, nil, nil), &buf)
+	if err != nil {
+		t.Error("Not expecting error viewing the configuration")
+	}
+	if strings.Contains(buf.String(), "secret-token") || !strings.Contains(buf.String(), cf.RedactedValue) {
+		t.Errorf("Expected the token to be redacted, got %s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "Contexts.default.Tenant.Name") {
+		t.Errorf("Expected the keys of the default context, got %s", buf.String())
+	}
+
+	buf.Reset()
+	err = viewConfig(newConfigContext(t, []string{"-output", "json"}, nil), &buf)
+	if err != nil {
+		t.Error("Not expecting error viewing the configuration")
+	}
+	var contexts cf.ContextsConfiguration
+	err = json.Unmarshal(buf.Bytes(), &contexts)
+	if err != nil || contexts.Contexts["default"].Token != cf.RedactedValue {
+		t.Errorf("Expected the configuration as JSON with the token redacted, got %s", buf.String())
+	}
+
+	err = unsetConfigValue(newConfigContext(t, []string{"-non-interactive"}, []string{"Tenant"}))
+	if err != nil {
+		t.Error("Not expecting error unsetting Tenant")
+	}
+	config, err = cf.LoadConfig()
+	if err != nil || config.Tenant != nil || config.CloudTarget != "http://localhost:9000" {
+		t.Errorf("Expected only the tenant to be removed, got %+v", config)
+	}
+
+	err = cf.SaveContexts(contextsOri)
+	if err != nil {
+		t.Error("Not expecting error when saving config file")
+	}
+}
@@ -11,7 +11,6 @@ package command
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"regexp"
 	"sort"
@@ -68,7 +67,7 @@ func GetDeploymentsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listDeployments(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -78,7 +77,7 @@ func GetDeploymentsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := showDeployment(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -88,7 +87,7 @@ func GetDeploymentsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listDeploymentHosts(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -98,7 +97,7 @@ func GetDeploymentsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listDeploymentVms(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -118,7 +117,7 @@ func GetDeploymentsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := enableClusterType(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -134,7 +133,7 @@ func GetDeploymentsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := disableClusterType(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -150,7 +149,7 @@ func GetDeploymentsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := updateImageDatastores(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -160,7 +159,7 @@ func GetDeploymentsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := pauseSystem(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -170,7 +169,7 @@ func GetDeploymentsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := pauseBackgroundTasks(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -180,7 +179,7 @@ func GetDeploymentsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := resumeSystem(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -190,7 +189,7 @@ func GetDeploymentsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := setDeploymentSecurityGroups(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -210,7 +209,7 @@ func GetDeploymentsCommand() cli.Command {
 						Action: func(c *cli.Context) {
 							err := deploymentMigrationPrepare(c)
 							if err != nil {
-								log.Fatal("Error: ", err)
+								exitWithError(c, err)
 							}
 						},
 					},
@@ -226,7 +225,7 @@ func GetDeploymentsCommand() cli.Command {
 						Action: func(c *cli.Context) {
 							err := deploymentMigrationFinalize(c)
 							if err != nil {
-								log.Fatal("Error: ", err)
+								exitWithError(c, err)
 							}
 						},
 					},
@@ -236,7 +235,7 @@ func GetDeploymentsCommand() cli.Command {
 						Action: func(c *cli.Context) {
 							err := showMigrationStatus(c)
 							if err != nil {
-								log.Fatal("Error: ", err)
+								exitWithError(c, err)
 							}
 						},
 					},
@@ -513,7 +512,7 @@ func updateImageDatastores(c *cli.Context) error {
 	}
 
 	if len(datastores) == 0 {
-		return fmt.Errorf("Please provide datastores using --datastores flag")
+		return usageError{"Please provide datastores using --datastores flag"}
 	}
 
 	imageDataStores := &photon.ImageDatastores{
@@ -551,7 +550,7 @@ func pauseSystem(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(pauseSystemTask.ID, c)
+	_, err = waitOnTaskOperation(pauseSystemTask, c)
 	if err != nil {
 		return err
 	}
@@ -576,7 +575,7 @@ func pauseBackgroundTasks(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(pauseBackgroundTask.ID, c)
+	_, err = waitOnTaskOperation(pauseBackgroundTask, c)
 	if err != nil {
 		return err
 	}
@@ -601,7 +600,7 @@ func resumeSystem(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(resumeSystemTask.ID, c)
+	_, err = waitOnTaskOperation(resumeSystemTask, c)
 	if err != nil {
 		return err
 	}
@@ -628,7 +627,7 @@ func setDeploymentSecurityGroups(c *cli.Context) error {
 		}
 		groups = c.Args()[0]
 	} else {
-		return fmt.Errorf("Usage: deployments set-security-groups [id] groups")
+		return usageError{"Usage: deployments set-security-groups [id] groups"}
 	}
 
 	items := regexp.MustCompile(`\s*,\s*`).Split(groups, -1)
@@ -646,7 +645,7 @@ func setDeploymentSecurityGroups(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
@@ -677,14 +676,14 @@ func enableClusterType(c *cli.Context) error {
 	}
 
 	if len(id) == 0 {
-		return fmt.Errorf("Please provide deployment id")
+		return usageError{"Please provide deployment id"}
 	}
 	if len(clusterType) == 0 {
-		return fmt.Errorf("Please provide cluster type using --type flag")
+		return usageError{"Please provide cluster type using --type flag"}
 	}
 
 	if len(imageID) == 0 {
-		return fmt.Errorf("Please provide image ID using --image-id flag")
+		return usageError{"Please provide image ID using --image-id flag"}
 	}
 
 	if confirmed(c.GlobalIsSet("non-interactive")) {
@@ -702,7 +701,7 @@ func enableClusterType(c *cli.Context) error {
 			return err
 		}
 
-		_, err = waitOnTaskOperation(task.ID, c)
+		_, err = waitOnTaskOperation(task, c)
 		if err != nil {
 			return err
 		}
@@ -731,10 +730,10 @@ func disableClusterType(c *cli.Context) error {
 	}
 
 	if len(id) == 0 {
-		return fmt.Errorf("Please provide deployment id")
+		return usageError{"Please provide deployment id"}
 	}
 	if len(clusterType) == 0 {
-		return fmt.Errorf("Please provide cluster type using --type flag")
+		return usageError{"Please provide cluster type using --type flag"}
 	}
 
 	if confirmed(c.GlobalIsSet("non-interactive")) {
@@ -752,7 +751,7 @@ func disableClusterType(c *cli.Context) error {
 			return err
 		}
 
-		_, err = waitOnTaskOperation(task.ID, c)
+		_, err = waitOnTaskOperation(task, c)
 		if err != nil {
 			return err
 		}
@@ -772,7 +771,7 @@ func deploymentMigrationPrepare(c *cli.Context) error {
 
 	sourceAddress := c.String("endpoint")
 	if len(sourceAddress) == 0 {
-		return fmt.Errorf("Please provide the API endpoint of the old control plane")
+		return usageError{"Please provide the API endpoint of the old control plane"}
 	}
 
 	client.Esxclient, err = client.GetClient(c.GlobalIsSet("non-interactive"))
@@ -793,7 +792,7 @@ func deploymentMigrationPrepare(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(initializeMigrate.ID, c)
+	_, err = waitOnTaskOperation(initializeMigrate, c)
 	if err != nil {
 		return err
 	}
@@ -811,7 +810,7 @@ func deploymentMigrationFinalize(c *cli.Context) error {
 
 	sourceAddress := c.String("endpoint")
 	if len(sourceAddress) == 0 {
-		return fmt.Errorf("Please provide the API endpoint of the old control plane")
+		return usageError{"Please provide the API endpoint of the old control plane"}
 	}
 
 	client.Esxclient, err = client.GetClient(c.GlobalIsSet("non-interactive"))
@@ -832,7 +831,7 @@ func deploymentMigrationFinalize(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(finalizeMigrate.ID, c)
+	_, err = waitOnTaskOperation(finalizeMigrate, c)
 	if err != nil {
 		return err
 	}
@@ -881,7 +880,7 @@ func showMigrationStatus(c *cli.Context) error {
 // find it by using the "list" API. The "automatic" retrieval assumes that there is only one deployment object present.
 func getDeploymentId(c *cli.Context) (id string, err error) {
 	if len(c.Args()) > 1 {
-		err = fmt.Errorf("Unknown arguments: %v.", c.Args()[1:])
+		err = usageError{fmt.Sprintf("Unknown arguments: %v.", c.Args()[1:])}
 		return
 	}
 
@@ -999,39 +998,39 @@ func validateDeploymentArguments(imageDatastoreNames []string, enableAuth bool,
 	enableVirtualNetwork bool, networkManagerAddress string, networkManagerUsername string, networkManagerPassword string,
 	enableStats bool, statsStoreEndpoint string, statsStorePort int) error {
 	if len(imageDatastoreNames) == 0 {
-		return fmt.Errorf("Image datastore names cannot be nil.")
+		return usageError{"Image datastore names cannot be nil."}
 	}
 	if enableAuth {
 		if oauthTenant == "" {
-			return fmt.Errorf("OAuth tenant cannot be nil when auth is enabled.")
+			return usageError{"OAuth tenant cannot be nil when auth is enabled."}
 		}
 		if oauthUsername == "" {
-			return fmt.Errorf("OAuth username cannot be nil when auth is enabled.")
+			return usageError{"OAuth username cannot be nil when auth is enabled."}
 		}
 		if oauthPassword == "" {
-			return fmt.Errorf("OAuth password cannot be nil when auth is enabled.")
+			return usageError{"OAuth password cannot be nil when auth is enabled."}
 		}
 		if len(oauthSecurityGroups) == 0 {
-			return fmt.Errorf("OAuth security groups cannot be nil when auth is enabled.")
+			return usageError{"OAuth security groups cannot be nil when auth is enabled."}
 		}
 	}
 	if enableVirtualNetwork {
 		if networkManagerAddress == "" {
-			return fmt.Errorf("Network manager address cannot be nil when virtual network is enabled.")
+			return usageError{"Network manager address cannot be nil when virtual network is enabled."}
 		}
 		if networkManagerUsername == "" {
-			return fmt.Errorf("Network manager username cannot be nil when virtual network is enabled.")
+			return usageError{"Network manager username cannot be nil when virtual network is enabled."}
 		}
 		if networkManagerPassword == "" {
-			return fmt.Errorf("Network manager password cannot be nil when virtual network is enabled.")
+			return usageError{"Network manager password cannot be nil when virtual network is enabled."}
 		}
 	}
 	if enableStats {
 		if statsStoreEndpoint == "" {
-			return fmt.Errorf("Stats store endpoint cannot be nil when stats is enabled.")
+			return usageError{"Stats store endpoint cannot be nil when stats is enabled."}
 		}
 		if statsStorePort == 0 {
-			return fmt.Errorf("Stats store port cannot be nil when stats is enabled.")
+			return usageError{"Stats store port cannot be nil when stats is enabled."}
 		}
 	}
 	return nil
@@ -11,7 +11,6 @@ package command
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"regexp"
 	"strconv"
@@ -19,6 +18,7 @@ import (
 	"text/tabwriter"
 
 	"github.com/vmware/photon-controller-cli/photon/client"
+	"github.com/vmware/photon-controller-cli/photon/utils"
 
 	"github.com/codegangsta/cli"
 	"github.com/vmware/photon-controller-go-sdk/photon"
@@ -71,7 +71,7 @@ func GetDiskCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createDisk(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -81,7 +81,7 @@ func GetDiskCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := deleteDisk(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -91,7 +91,7 @@ func GetDiskCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := showDisk(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -115,11 +115,13 @@ func GetDiskCommand() cli.Command {
 						Name:  "name, n",
 						Usage: "disk name",
 					},
+					filterFlag("state=DETACHED,flavor~=ssd"),
+					sortByFlag(".capacityGb"),
 				},
 				Action: func(c *cli.Context) {
 					err := listDisks(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -135,7 +137,7 @@ func GetDiskCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getDiskTasks(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -195,7 +197,7 @@ func createDisk(c *cli.Context) error {
 	}
 
 	if len(name) == 0 || len(flavor) == 0 {
-		return fmt.Errorf("please provide disk name and flavor")
+		return usageError{"please provide disk name and flavor"}
 	}
 
 	affinitiesList, err := parseAffinitiesListFromFlag(affinities)
@@ -222,7 +224,7 @@ func createDisk(c *cli.Context) error {
 			return err
 		}
 
-		_, err = waitOnTaskOperation(createTask.ID, c)
+		_, err = waitOnTaskOperation(createTask, c)
 		if err != nil {
 			return err
 		}
@@ -252,7 +254,7 @@ func deleteDisk(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(deleteTask.ID, c)
+	_, err = waitOnTaskOperation(deleteTask, c)
 	if err != nil {
 		return err
 	}
@@ -286,6 +288,8 @@ func showDisk(c *cli.Context) error {
 		scriptVMs := strings.Replace(vms, " ", ",", -1)
 		fmt.Printf("%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", disk.ID, disk.Name,
 			disk.State, disk.Kind, disk.Flavor, disk.CapacityGB, disk.Datastore, scriptTag, scriptVMs)
+	} else if utils.NeedsFormatting(c) {
+		utils.FormatObject(disk, os.Stdout, c)
 	} else {
 		fmt.Println("Disk ID: ", disk.ID)
 		fmt.Println("  Name:       ", disk.Name)
@@ -307,6 +311,10 @@ func listDisks(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	err = checkFilterAndSort(c)
+	if err != nil {
+		return err
+	}
 	tenantName := c.String("tenant")
 	projectName := c.String("project")
 	summaryView := c.IsSet("summary")
@@ -335,6 +343,11 @@ func listDisks(c *cli.Context) error {
 		return err
 	}
 
+	err = filterAndSortList(&diskList.Items, c)
+	if err != nil {
+		return err
+	}
+
 	stateCount := make(map[string]int)
 	for _, disk := range diskList.Items {
 		stateCount[disk.State]++
@@ -346,6 +359,8 @@ func listDisks(c *cli.Context) error {
 				fmt.Printf("%s\t%s\t%s\n", disk.ID, disk.Name, disk.State)
 			}
 		}
+	} else if utils.NeedsFormatting(c) {
+		utils.FormatObjects(diskList.Items, os.Stdout, c)
 	} else {
 		if !summaryView {
 			w := new(tabwriter.Writer)
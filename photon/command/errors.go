@@ -0,0 +1,209 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/vmware/photon-controller-go-sdk/photon"
+
+	"github.com/vmware/photon-controller-cli/photon/utils"
+)
+
+// Exit codes of the CLI, so that scripts can tell why a command failed.
+// They are documented in the README and must not change.
+const (
+	ExitOK = 0
+	// Any error not listed below
+	ExitError = 1
+	// Invalid arguments or options
+	ExitUsage = 2
+	// The API rejected the access token or the user is not allowed to do this
+	ExitAuth = 3
+	// The API did not find what the command refers to
+	ExitNotFound = 4
+	// The API rejected the request, e.g. invalid settings or a conflicting state
+	ExitInvalidRequest = 5
+	// The task started by the command ended in the ERROR state
+	ExitTaskError = 6
+	// The task started by the command did not end in time
+	ExitTimeout = 7
+	// The API could not be reached, or failed to process the request
+	ExitUnavailable = 8
+	// Waiting was interrupted with Ctrl-C, as shells report for SIGINT
+	ExitInterrupted = 130
+)
+
+// Returned by commands run with --async once their task is started, to end them
+// successfully without waiting for the task
+var errTaskStarted = errors.New("Task started")
+
+// Error given by invalid arguments
+type usageError struct {
+	message string
+}
+
+func (e usageError) Error() string {
+	return e.message
+}
+
+// Error given when something the command refers to was not found, when the CLI looks
+// it up itself rather than the API
+type notFoundError struct {
+	message string
+}
+
+func (e notFoundError) Error() string {
+	return e.message
+}
+
+// Error given when something did not reach the expected state in time
+type timeoutError struct {
+	message string
+}
+
+func (e timeoutError) Error() string {
+	return e.message
+}
+
+// Error given when waiting was interrupted with Ctrl-C, telling how to resume
+type interruptedError struct {
+	message string
+}
+
+func (e interruptedError) Error() string {
+	return e.message
+}
+
+// Returns the error telling how to resume waiting for tasks still running
+func newTasksInterruptedError(ids []string) interruptedError {
+	if len(ids) == 1 {
+		return interruptedError{fmt.Sprintf("task %s still running; resume with `photon task monitor %s`",
+			ids[0], ids[0])}
+	}
+	return interruptedError{fmt.Sprintf("tasks %s still running; resume with `photon task wait %s`",
+		strings.Join(ids, ", "), strings.Join(ids, " "))}
+}
+
+// Error given when a task did not end in time, telling how to keep following it
+type taskTimeoutError struct {
+	photon.TaskTimeoutError
+	timeout time.Duration
+}
+
+func (e taskTimeoutError) Error() string {
+	return fmt.Sprintf("Timed out after %s waiting for task %s, which may still be running. "+
+		"Resume with 'photon task monitor %s'", e.timeout, e.ID, e.ID)
+}
+
+func (e taskTimeoutError) Unwrap() error {
+	return e.TaskTimeoutError
+}
+
+// Description of an error, printed with -o when a command fails
+type errorInfo struct {
+	Kind       string `json:"kind"`
+	ExitCode   int    `json:"exitCode"`
+	Message    string `json:"message"`
+	Code       string `json:"code,omitempty"`
+	HttpStatus int    `json:"httpStatus,omitempty"`
+	TaskID     string `json:"taskId,omitempty"`
+}
+
+// Returns the kind and exit code of an error
+func classifyError(err error) errorInfo {
+	info := errorInfo{Kind: "Error", ExitCode: ExitError, Message: err.Error()}
+
+	var usageErr usageError
+	var interruptedErr interruptedError
+	var notFoundErr notFoundError
+	var apiErr photon.ApiError
+	var httpErr photon.HttpError
+	var taskErr photon.TaskError
+	var timeoutErr photon.TaskTimeoutError
+	var otherTimeoutErr timeoutError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &usageErr):
+		info.Kind, info.ExitCode = "Usage", ExitUsage
+	case errors.As(err, &interruptedErr):
+		info.Kind, info.ExitCode = "Interrupted", ExitInterrupted
+	case errors.As(err, &notFoundErr):
+		info.Kind, info.ExitCode = "NotFound", ExitNotFound
+	case errors.As(err, &taskErr):
+		info.Kind, info.ExitCode = "TaskError", ExitTaskError
+		info.TaskID = taskErr.ID
+		if len(taskErr.Step.Errors) != 0 {
+			info.Code = taskErr.Step.Errors[0].Code
+		}
+	case errors.As(err, &timeoutErr):
+		info.Kind, info.ExitCode = "Timeout", ExitTimeout
+		info.TaskID = timeoutErr.ID
+	case errors.As(err, &otherTimeoutErr):
+		info.Kind, info.ExitCode = "Timeout", ExitTimeout
+	case errors.As(err, &apiErr):
+		info.Kind, info.ExitCode = classifyHttpStatus(apiErr.HttpStatusCode)
+		info.Code = apiErr.Code
+		info.HttpStatus = apiErr.HttpStatusCode
+		if strings.HasSuffix(apiErr.Code, "NotFound") {
+			info.Kind, info.ExitCode = "NotFound", ExitNotFound
+		}
+	case errors.As(err, &httpErr):
+		info.Kind, info.ExitCode = classifyHttpStatus(httpErr.StatusCode)
+		info.HttpStatus = httpErr.StatusCode
+	case errors.As(err, &netErr):
+		info.Kind, info.ExitCode = "Unavailable", ExitUnavailable
+	}
+	return info
+}
+
+// Returns the kind and exit code of an HTTP error status
+func classifyHttpStatus(status int) (string, int) {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return "Auth", ExitAuth
+	case status == http.StatusNotFound:
+		return "NotFound", ExitNotFound
+	case status >= 400 && status < 500:
+		return "InvalidRequest", ExitInvalidRequest
+	case status >= 500:
+		return "Unavailable", ExitUnavailable
+	}
+	return "Error", ExitError
+}
+
+// Prints the error of a command, as an object with -o json or -o yaml, and returns
+// its exit code
+func printError(c *cli.Context, err error, w io.Writer) int {
+	info := classifyError(err)
+	switch c.GlobalString("output") {
+	case "json", "yaml":
+		utils.FormatObject(map[string]errorInfo{"error": info}, w, c)
+	default:
+		fmt.Fprintf(w, "Error: %s\n", err)
+	}
+	return info.ExitCode
+}
+
+// Ends a command that failed, with the exit code of its error
+func exitWithError(c *cli.Context, err error) {
+	if errors.Is(err, errTaskStarted) {
+		return
+	}
+	os.Exit(printError(c, err, os.Stderr))
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codegangsta/cli"
+	"github.com/vmware/photon-controller-go-sdk/photon"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		err      error
+		exitCode int
+	}{
+		{errors.New("unknown"), ExitError},
+		{checkArgNum([]string{"a", "b"}, 1, "vm show <id>"), ExitUsage},
+		{photon.ApiError{Code: "VmNotFound", HttpStatusCode: 404}, ExitNotFound},
+		{photon.ApiError{Code: "Unauthorized", HttpStatusCode: 401}, ExitAuth},
+		{photon.ApiError{Code: "InvalidEntity", HttpStatusCode: 400}, ExitInvalidRequest},
+		{photon.HttpError{StatusCode: 503}, ExitUnavailable},
+		{photon.HttpError{StatusCode: 403}, ExitAuth},
+		{photon.TaskError{ID: "task-id"}, ExitTaskError},
+		{fmt.Errorf("%w\nAPI Errors: []", photon.TaskError{ID: "task-id"}), ExitTaskError},
+		{photon.TaskTimeoutError{ID: "task-id"}, ExitTimeout},
+		{timeoutError{"Timed out"}, ExitTimeout},
+		{newTasksInterruptedError([]string{"task-id"}), ExitInterrupted},
+		{notFoundError{"Cannot find project named 'p'"}, ExitNotFound},
+		{taskTimeoutError{photon.TaskTimeoutError{ID: "task-id"}, time.Minute}, ExitTimeout},
+		{&url.Error{Op: "Get", URL: "https://photon", Err: &timeoutNetError{}}, ExitUnavailable},
+	}
+	for _, testCase := range cases {
+		info := classifyError(testCase.err)
+		if info.ExitCode != testCase.exitCode {
+			t.Errorf("Expected exit code %d for '%s', got %d", testCase.exitCode, testCase.err, info.ExitCode)
+		}
+	}
+}
+
+// Invalid arguments are found before calling the API, and exit with ExitUsage
+func TestArgumentValidationExitCode(t *testing.T) {
+	globalSet := flag.NewFlagSet("test", 0)
+	globalSet.Bool("non-interactive", true, "doc")
+	err := globalSet.Parse([]string{"--non-interactive"})
+	if err != nil {
+		t.Error("Not expecting arguments parsing to fail")
+	}
+	set := flag.NewFlagSet("test", 0)
+	set.String("name", "name", "flavor name")
+	set.String("kind", "unknown", "flavor kind")
+	set.String("cost", "", "flavor cost")
+	cxt := cli.NewContext(nil, set, cli.NewContext(nil, globalSet, nil))
+
+	_, limitsErr := parseLimitsListFromFlag("vm.cost")
+	cases := []error{
+		createFlavor(cxt, &bytes.Buffer{}),
+		limitsErr,
+		validateDeploymentArguments(nil, false, "", "", "", nil, false, "", "", "", false, "", 0),
+	}
+	for _, err := range cases {
+		if err == nil {
+			t.Error("Expected an error for the invalid arguments")
+			continue
+		}
+		info := classifyError(err)
+		if info.ExitCode != ExitUsage {
+			t.Errorf("Expected exit code %d for '%s', got %d", ExitUsage, err, info.ExitCode)
+		}
+	}
+}
+
+func TestPrintError(t *testing.T) {
+	var buf bytes.Buffer
+	exitCode := printError(newConfigContext(t, []string{"-output", "json"}, nil),
+		photon.TaskError{ID: "task-id"}, &buf)
+	if exitCode != ExitTaskError {
+		t.Errorf("Expected exit code %d, got %d", ExitTaskError, exitCode)
+	}
+	var output map[string]errorInfo
+	err := json.Unmarshal(buf.Bytes(), &output)
+	if err != nil || output["error"].Kind != "TaskError" || output["error"].TaskID != "task-id" {
+		t.Errorf("Expected a JSON error object, got %s", buf.String())
+	}
+
+	buf.Reset()
+	exitCode = printError(newConfigContext(t, nil, nil), photon.ApiError{Code: "VmNotFound", HttpStatusCode: 404}, &buf)
+	if exitCode != ExitNotFound || buf.String()[:7] != "Error: " {
+		t.Errorf("Expected the error message, got %d and %s", exitCode, buf.String())
+	}
+
+	buf.Reset()
+	exitCode = printError(newConfigContext(t, []string{"-output", "yaml"}, nil), notFoundError{"Not found"}, &buf)
+	if exitCode != ExitNotFound || !strings.HasPrefix(buf.String(), "error:\n  kind: NotFound\n") {
+		t.Errorf("Expected a YAML error object, got %d and %s", exitCode, buf.String())
+	}
+
+	buf.Reset()
+	printError(newConfigContext(t, []string{"-output", "csv"}, nil), notFoundError{"Not found"}, &buf)
+	if buf.String() != "Error: Not found\n" {
+		t.Errorf("Expected the error message with -o csv, got %s", buf.String())
+	}
+}
+
+// Network error as given when a connection times out
+type timeoutNetError struct{}
+
+func (e *timeoutNetError) Error() string   { return "i/o timeout" }
+func (e *timeoutNetError) Timeout() bool   { return true }
+func (e *timeoutNetError) Temporary() bool { return true }
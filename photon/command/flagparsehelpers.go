@@ -15,6 +15,9 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/vmware/photon-controller-cli/photon/utils"
+
+	"github.com/codegangsta/cli"
 	"github.com/vmware/photon-controller-go-sdk/photon"
 )
 
@@ -26,13 +29,13 @@ func parseLimitsListFromFlag(limits string) ([]photon.QuotaLineItem, error) {
 		for i := 0; i < len(limitsListOri); i++ {
 			limit := strings.Fields(limitsListOri[i])
 			if len(limit) != 3 {
-				return limitsList, fmt.Errorf("Error parsing limits, should be: <key> <value> <unit>, <key> <value> <unit>...")
+				return limitsList, usageError{"Error parsing limits, should be: <key> <value> <unit>, <key> <value> <unit>..."}
 			}
 
 			key := limit[0]
 			value, err := strconv.ParseFloat(limit[1], 64)
 			if err != nil {
-				return limitsList, fmt.Errorf("Error: %s. Please provide float as value", err.Error())
+				return limitsList, fmt.Errorf("%s. Please provide float as value", err.Error())
 			}
 			unit := limit[2]
 
@@ -50,7 +53,7 @@ func parseAffinitiesListFromFlag(affinities string) ([]photon.LocalitySpec, erro
 		for i := 0; i < len(affinitiesListOri); i++ {
 			affinity := regexp.MustCompile(`\s*:\s*`).Split(affinitiesListOri[i], 2)
 			if len(affinity) != 2 {
-				return affinitiesList, fmt.Errorf("Error parsing affinities, should be: <kind>:<id>, <kind>:<id>...")
+				return affinitiesList, usageError{"Error parsing affinities, should be: <kind>:<id>, <kind>:<id>..."}
 			}
 
 			kind := affinity[0]
@@ -70,7 +73,7 @@ func parseDisksListFromFlag(disks string) ([]photon.AttachedDisk, error) {
 		for i := 0; i < len(disksListOri); i++ {
 			disk := strings.Fields(disksListOri[i])
 			if len(disk) != 3 {
-				return disksList, fmt.Errorf("Error parsing disks, should be: <name> <flavor> <boot=true/capacity>...")
+				return disksList, usageError{"Error parsing disks, should be: <name> <flavor> <boot=true/capacity>..."}
 			}
 
 			name := disk[0]
@@ -97,7 +100,7 @@ func parseMapFromFlag(cmdFlag string) (map[string]string, error) {
 		for i := 0; i < len(entries); i++ {
 			entry := regexp.MustCompile(`\s*:\s*`).Split(entries[i], -1)
 			if len(entry) != 2 {
-				return newMap, fmt.Errorf("Error parsing the command flag, should be: <key>:<value>, <key>:<value>...")
+				return newMap, usageError{"Error parsing the command flag, should be: <key>:<value>, <key>:<value>..."}
 			}
 
 			key := entry[0]
@@ -108,3 +111,35 @@ func parseMapFromFlag(cmdFlag string) (map[string]string, error) {
 	}
 	return newMap, nil
 }
+
+// Returns the -filter flag of a list command, with an example using the fields of
+// the objects it lists
+func filterFlag(example string) cli.Flag {
+	return cli.StringFlag{
+		Name:  "filter",
+		Usage: "Filter the list by fields (=, != or ~= for a regexp), e.g. " + example,
+	}
+}
+
+// Returns the -sort-by flag of a list command, with an example field of the objects it lists
+func sortByFlag(example string) cli.Flag {
+	return cli.StringFlag{
+		Name:  "sort-by",
+		Usage: "Sort the list by a field, e.g. " + example,
+	}
+}
+
+// Checks the -filter and -sort-by flags, so that a mistake in them is reported before
+// calling the API
+func checkFilterAndSort(c *cli.Context) error {
+	err := utils.ValidateFilterAndSort(c.String("filter"), c.String("sort-by"))
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	return nil
+}
+
+// Filter and sort a list of objects (e.g. &vmList.Items) with the -filter and -sort-by flags
+func filterAndSortList(list interface{}, c *cli.Context) error {
+	return utils.FilterAndSortList(list, c.String("filter"), c.String("sort-by"))
+}
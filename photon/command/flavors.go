@@ -12,7 +12,6 @@ package command
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"text/tabwriter"
 
@@ -54,7 +53,7 @@ func GetFlavorsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createFlavor(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -64,7 +63,7 @@ func GetFlavorsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := deleteFlavor(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -84,7 +83,7 @@ func GetFlavorsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listFlavors(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -94,7 +93,7 @@ func GetFlavorsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := showFlavor(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -110,7 +109,7 @@ func GetFlavorsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getFlavorTasks(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -150,11 +149,11 @@ func createFlavor(c *cli.Context, w io.Writer) error {
 	}
 
 	if len(name) == 0 {
-		return fmt.Errorf("Please provide flavor name")
+		return usageError{"Please provide flavor name"}
 	}
 
 	if len(kind) == 0 || (kind != "persistent-disk" && kind != "ephemeral-disk" && kind != "vm") {
-		return fmt.Errorf("Please provide flavor kind: persistent-disk, ephemeral-disk, or vm")
+		return usageError{"Please provide flavor kind: persistent-disk, ephemeral-disk, or vm"}
 	}
 
 	createSpec := &photon.FlavorCreateSpec{
@@ -182,7 +181,7 @@ func createFlavor(c *cli.Context, w io.Writer) error {
 		if err != nil {
 			return err
 		}
-		flavorId, err := waitOnTaskOperation(createTask.ID, c)
+		flavorId, err := waitOnTaskOperation(createTask, c)
 		if err != nil {
 			return err
 		}
@@ -218,7 +217,7 @@ func deleteFlavor(c *cli.Context, w io.Writer) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(deleteTask.ID, c)
+	_, err = waitOnTaskOperation(deleteTask, c)
 	if err != nil {
 		return err
 	}
@@ -12,7 +12,6 @@ package command
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"regexp"
 	"strings"
@@ -79,7 +78,7 @@ func GetHostsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createHost(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -89,27 +88,41 @@ func GetHostsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := deleteHost(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
 			{
 				Name:  "list",
 				Usage: "List all the hosts",
+				Flags: []cli.Flag{
+					filterFlag("state=READY,usageTags=CLOUD"),
+					sortByFlag(".address"),
+					cli.BoolFlag{
+						Name:  "watch, w",
+						Usage: "Poll for changes and print the rows that changed, until interrupted",
+					},
+				},
 				Action: func(c *cli.Context) {
 					err := listHosts(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
 			{
 				Name:  "show",
 				Usage: "Show host info with specified id",
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "watch, w",
+						Usage: "Poll for changes and print the rows that changed, until interrupted",
+					},
+				},
 				Action: func(c *cli.Context) {
 					err := showHost(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -119,7 +132,7 @@ func GetHostsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listHostVMs(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -129,7 +142,7 @@ func GetHostsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := setHostAvailabilityZone(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -145,7 +158,7 @@ func GetHostsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getHostTasks(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -155,7 +168,7 @@ func GetHostsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := suspendHost(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -165,7 +178,7 @@ func GetHostsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := resumeHost(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -175,7 +188,7 @@ func GetHostsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := enterMaintenanceMode(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -185,7 +198,7 @@ func GetHostsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := exitMaintenanceMode(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -263,7 +276,7 @@ func createHost(c *cli.Context, w io.Writer) error {
 	if err != nil {
 		return err
 	}
-	id, err := waitOnTaskOperation(createTask.ID, c)
+	id, err := waitOnTaskOperation(createTask, c)
 	if err != nil {
 		return err
 	}
@@ -297,7 +310,7 @@ func deleteHost(c *cli.Context, w io.Writer) error {
 	if err != nil {
 		return err
 	}
-	_, err = waitOnTaskOperation(deleteTask.ID, c)
+	_, err = waitOnTaskOperation(deleteTask, c)
 	if err != nil {
 		return err
 	}
@@ -314,6 +327,10 @@ func listHosts(c *cli.Context, w io.Writer) error {
 	if err != nil {
 		return err
 	}
+	err = checkFilterAndSort(c)
+	if err != nil {
+		return err
+	}
 
 	client.Esxclient, err = client.GetClient(utils.IsNonInteractive(c))
 	if err != nil {
@@ -333,11 +350,30 @@ func listHosts(c *cli.Context, w io.Writer) error {
 	}
 	id := deployments.Items[0].ID
 
+	if c.Bool("watch") {
+		return watch(c, w, func() (*watchSnapshot, error) {
+			hosts, err := client.Esxclient.Deployments.GetHosts(id)
+			if err != nil {
+				return nil, err
+			}
+			err = filterAndSortList(&hosts.Items, c)
+			if err != nil {
+				return nil, err
+			}
+			return hostListWatchSnapshot(hosts.Items), nil
+		})
+	}
+
 	hosts, err := client.Esxclient.Deployments.GetHosts(id)
 	if err != nil {
 		return err
 	}
 
+	err = filterAndSortList(&hosts.Items, c)
+	if err != nil {
+		return err
+	}
+
 	err = printHostList(hosts.Items, w, c)
 	if err != nil {
 		return err
@@ -358,6 +394,16 @@ func showHost(c *cli.Context, w io.Writer) error {
 		return err
 	}
 
+	if c.Bool("watch") {
+		return watch(c, w, func() (*watchSnapshot, error) {
+			host, err := client.Esxclient.Hosts.Get(id)
+			if err != nil {
+				return nil, err
+			}
+			return objectWatchSnapshot(host.ID, host)
+		})
+	}
+
 	host, err := client.Esxclient.Hosts.Get(id)
 	if err != nil {
 		return err
@@ -407,7 +453,7 @@ func setHostAvailabilityZone(c *cli.Context, w io.Writer) error {
 	if err != nil {
 		return err
 	}
-	id, err = waitOnTaskOperation(setTask.ID, c)
+	id, err = waitOnTaskOperation(setTask, c)
 	if err != nil {
 		return err
 	}
@@ -494,7 +540,7 @@ func suspendHost(c *cli.Context, w io.Writer) error {
 	if err != nil {
 		return err
 	}
-	_, err = waitOnTaskOperation(suspendTask.ID, c)
+	_, err = waitOnTaskOperation(suspendTask, c)
 	if err != nil {
 		return err
 	}
@@ -520,7 +566,7 @@ func resumeHost(c *cli.Context, w io.Writer) error {
 	if err != nil {
 		return err
 	}
-	_, err = waitOnTaskOperation(resumeTask.ID, c)
+	_, err = waitOnTaskOperation(resumeTask, c)
 	if err != nil {
 		return err
 	}
@@ -546,7 +592,7 @@ func enterMaintenanceMode(c *cli.Context, w io.Writer) error {
 	if err != nil {
 		return err
 	}
-	_, err = waitOnTaskOperation(enterTask.ID, c)
+	_, err = waitOnTaskOperation(enterTask, c)
 	if err != nil {
 		return err
 	}
@@ -572,7 +618,7 @@ func exitMaintenanceMode(c *cli.Context, w io.Writer) error {
 	if err != nil {
 		return err
 	}
-	_, err = waitOnTaskOperation(exitTask.ID, c)
+	_, err = waitOnTaskOperation(exitTask, c)
 	if err != nil {
 		return err
 	}
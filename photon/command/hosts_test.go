@@ -220,6 +220,18 @@ func TestListHosts(t *testing.T) {
 	} else if !strings.Contains(err.Error(), "There are multiple deployments") {
 		t.Error("listHosts failed, but not with expected error message: " + err.Error())
 	}
+
+	// An invalid filter is reported before calling the API
+	commandFlags = flag.NewFlagSet("command-flags", flag.ContinueOnError)
+	commandFlags.String("filter", "", "filter")
+	err = commandFlags.Parse([]string{"--filter=state"})
+	if err != nil {
+		t.Error(err)
+	}
+	err = listHosts(cli.NewContext(nil, commandFlags, globalCtx), os.Stdout)
+	if err == nil || classifyError(err).ExitCode != ExitUsage {
+		t.Errorf("Expected a usage error for an invalid filter, got %v", err)
+	}
 }
 
 func mockHostsForList(t *testing.T, server *httptest.Server) error {
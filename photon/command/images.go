@@ -12,7 +12,6 @@ package command
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -52,7 +51,7 @@ func GetImagesCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createImage(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -62,7 +61,7 @@ func GetImagesCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := deleteImage(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -74,11 +73,13 @@ func GetImagesCommand() cli.Command {
 						Name:  "name, n",
 						Usage: "Image name",
 					},
+					filterFlag("state=READY,name~=^ubuntu"),
+					sortByFlag(".size"),
 				},
 				Action: func(c *cli.Context) {
 					err := listImages(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -88,7 +89,7 @@ func GetImagesCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := showImage(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -104,7 +105,7 @@ func GetImagesCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getImageTasks(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -116,7 +117,7 @@ func GetImagesCommand() cli.Command {
 // Create an image
 func createImage(c *cli.Context, w io.Writer) error {
 	if len(c.Args()) > 1 {
-		return fmt.Errorf("Unknown argument: %v", c.Args()[1:])
+		return usageError{fmt.Sprintf("Unknown argument: %v", c.Args()[1:])}
 	}
 	path := c.Args().First()
 	name := c.String("name")
@@ -131,7 +132,7 @@ func createImage(c *cli.Context, w io.Writer) error {
 	}
 
 	if len(path) == 0 {
-		return fmt.Errorf("Please provide image path")
+		return usageError{"Please provide image path"}
 	}
 
 	path, err := filepath.Abs(path)
@@ -187,7 +188,7 @@ func createImage(c *cli.Context, w io.Writer) error {
 		return err
 	}
 
-	imageID, err := waitOnTaskOperation(uploadTask.ID, c)
+	imageID, err := waitOnTaskOperation(uploadTask, c)
 	if err != nil {
 		return err
 	}
@@ -227,7 +228,7 @@ func deleteImage(c *cli.Context) error {
 			return err
 		}
 
-		_, err = waitOnTaskOperation(deleteTask.ID, c)
+		_, err = waitOnTaskOperation(deleteTask, c)
 		if err != nil {
 			return err
 		}
@@ -244,6 +245,10 @@ func listImages(c *cli.Context, w io.Writer) error {
 	if err != nil {
 		return err
 	}
+	err = checkFilterAndSort(c)
+	if err != nil {
+		return err
+	}
 	client.Esxclient, err = client.GetClient(utils.IsNonInteractive(c))
 	if err != nil {
 		return err
@@ -258,6 +263,11 @@ func listImages(c *cli.Context, w io.Writer) error {
 		return err
 	}
 
+	err = filterAndSortList(&images.Items, c)
+	if err != nil {
+		return err
+	}
+
 	if c.GlobalIsSet("non-interactive") {
 		for _, image := range images.Items {
 			fmt.Printf("%s\t%s\t%s\t%d\t%s\t%s\t%s\n", image.ID, image.Name, image.State, image.Size,
@@ -296,7 +306,7 @@ func showImage(c *cli.Context, w io.Writer) error {
 	}
 
 	if len(id) == 0 {
-		return fmt.Errorf("Please provide image id")
+		return usageError{"Please provide image id"}
 	}
 
 	var err error
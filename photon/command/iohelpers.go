@@ -16,7 +16,6 @@ import (
 	"os"
 	"strconv"
 	"strings"
-	"sync"
 	"text/tabwriter"
 	"time"
 
@@ -35,7 +34,7 @@ func askForInput(msg string, name string) (string, error) {
 		return name, nil
 	}
 
-	fmt.Printf(msg)
+	fmt.Print(msg)
 	consoleReader := bufio.NewReader(os.Stdin)
 
 	line, err := consoleReader.ReadString('\n')
@@ -92,7 +91,7 @@ func askForLimitList(limitsList []photon.QuotaLineItem) ([]photon.QuotaLineItem,
 			}
 			value, err := strconv.ParseFloat(valueStr, 64)
 			if err != nil {
-				return limitsList, fmt.Errorf("Error: %s. Please provide float as value", err.Error())
+				return limitsList, fmt.Errorf("%s. Please provide float as value", err.Error())
 			}
 
 			unit, err := askForInput("Unit: ", "")
@@ -104,7 +103,7 @@ func askForLimitList(limitsList []photon.QuotaLineItem) ([]photon.QuotaLineItem,
 	}
 
 	if len(limitsList) == 0 {
-		return limitsList, fmt.Errorf("Please provide at least 1 limit")
+		return limitsList, usageError{"Please provide at least 1 limit"}
 	}
 
 	return limitsList, nil
@@ -195,19 +194,15 @@ func quotaLineItemListToString(qliList []photon.QuotaLineItem) string {
 // If the time is zero or negative, returns the string "-"
 // We do this because a timestamp of zero in a task means "undefined"
 func timestampToString(timestamp int64) string {
-	if timestamp <= 0 {
-		return "-"
-	} else {
-		return time.Unix(timestamp/1000, 0).Format("2006-01-02 03:04:05.00")
-	}
+	return utils.TimestampToString(timestamp)
 }
 
 func checkArgNum(args []string, num int, usage string) error {
 	if len(args) < num {
-		return fmt.Errorf("Please provide argument. Usage: %s", usage)
+		return usageError{fmt.Sprintf("Please provide argument. Usage: %s", usage)}
 	}
 	if len(args) > num {
-		return fmt.Errorf("Unknown arguments: %v. Usage: %s", args[num:], usage)
+		return usageError{fmt.Sprintf("Unknown arguments: %v. Usage: %s", args[num:], usage)}
 	}
 	return nil
 }
@@ -262,7 +257,7 @@ func askForVMDiskList(disksList []photon.AttachedDisk) ([]photon.AttachedDisk, e
 				}
 				capacity, err := strconv.Atoi(capacityGB)
 				if err != nil {
-					return disksList, fmt.Errorf("Error: %s. Please provide int as value", err.Error())
+					return disksList, fmt.Errorf("%s. Please provide int as value", err.Error())
 				}
 				disk = photon.AttachedDisk{
 					Name:       name,
@@ -278,7 +273,7 @@ func askForVMDiskList(disksList []photon.AttachedDisk) ([]photon.AttachedDisk, e
 	}
 
 	if len(disksList) == 0 {
-		return disksList, fmt.Errorf("Please provide at least 1 disk")
+		return disksList, usageError{"Please provide at least 1 disk"}
 	}
 
 	return disksList, nil
@@ -403,7 +398,7 @@ func getVMNetworks(id string, isScripting bool) (networks []interface{}, err err
 	}
 
 	if isScripting {
-		task, err = client.Esxclient.Tasks.Wait(task.ID)
+		task, err = waitTask(task.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -475,15 +470,13 @@ func clearConfigTenant(id string) error {
 	}
 
 	if len(id) == 0 || config.Tenant.ID == id {
-		config.Tenant = nil
-		err = cf.SaveConfig(config)
-		if err != nil {
-			return err
-		}
-		err = clearConfigProject("")
-		if err != nil {
-			return err
-		}
+		return cf.UpdateConfig(func(config *cf.Configuration) error {
+			if config.Tenant != nil && (len(id) == 0 || config.Tenant.ID == id) {
+				config.Tenant = nil
+				config.Project = nil
+			}
+			return nil
+		})
 	}
 	return nil
 }
@@ -504,14 +497,17 @@ func findTenantID(name string) (string, error) {
 		}
 	}
 	if !found {
-		return "", fmt.Errorf("Tenant name '%s' not found", name)
+		return "", notFoundError{fmt.Sprintf("Tenant name '%s' not found", name)}
 	}
 	return id, nil
 }
 
 // Verifies and gets tenant name and id for commands specifying tenant
-// Returns tenant in config file if name is empty
+// Returns the overridden tenant, or else the tenant in config file, if name is empty
 func verifyTenant(name string) (*cf.TenantConfiguration, error) {
+	if len(name) == 0 {
+		name = cf.ConfigOverrides.Tenant
+	}
 	if len(name) != 0 {
 		tenantID, err := findTenantID(name)
 		if len(tenantID) == 0 || err != nil {
@@ -525,7 +521,7 @@ func verifyTenant(name string) (*cf.TenantConfiguration, error) {
 		return nil, err
 	}
 	if config.Tenant == nil {
-		return nil, fmt.Errorf("Error: Set tenant first using 'tenant set <name>' or '-t <name>' option")
+		return nil, usageError{"Set tenant first using 'tenant set <name>' or '-t <name>' option"}
 	}
 
 	return config.Tenant, nil
@@ -543,11 +539,12 @@ func clearConfigProject(id string) error {
 	}
 
 	if len(id) == 0 || config.Project.ID == id {
-		config.Project = nil
-		err = cf.SaveConfig(config)
-		if err != nil {
-			return err
-		}
+		return cf.UpdateConfig(func(config *cf.Configuration) error {
+			if config.Project != nil && (len(id) == 0 || config.Project.ID == id) {
+				config.Project = nil
+			}
+			return nil
+		})
 	}
 	return nil
 }
@@ -561,10 +558,10 @@ func findResourceTicket(tenantID string, name string) (*photon.ResourceTicket, e
 	rtList := tickets.Items
 
 	if len(rtList) < 1 {
-		return nil, fmt.Errorf("Error: Cannot find resource ticket named '%s'", name)
+		return nil, notFoundError{fmt.Sprintf("Cannot find resource ticket named '%s'", name)}
 	}
 	if len(rtList) > 1 {
-		return nil, fmt.Errorf("Error: Found more than 1 resource ticket named '%s'", name)
+		return nil, fmt.Errorf("Found more than 1 resource ticket named '%s'", name)
 	}
 
 	return &rtList[0], nil
@@ -579,18 +576,21 @@ func findProject(tenantID string, name string) (*photon.ProjectCompact, error) {
 	pList := tickets.Items
 
 	if len(pList) < 1 {
-		return nil, fmt.Errorf("Error: Cannot find project named '%s'", name)
+		return nil, notFoundError{fmt.Sprintf("Cannot find project named '%s'", name)}
 	}
 	if len(pList) > 1 {
-		return nil, fmt.Errorf("Error: Found more than 1 projects named '%s'", name)
+		return nil, fmt.Errorf("Found more than 1 projects named '%s'", name)
 	}
 
 	return &pList[0], nil
 }
 
 // Verifies and gets project name and id for commands specifying project
-// Returns project in config file if name is empty
+// Returns the overridden project, or else the project in config file, if name is empty
 func verifyProject(tenantID string, name string) (*cf.ProjectConfiguration, error) {
+	if len(name) == 0 {
+		name = cf.ConfigOverrides.Project
+	}
 	if len(name) != 0 {
 		project, err := findProject(tenantID, name)
 		if err != nil {
@@ -604,7 +604,7 @@ func verifyProject(tenantID string, name string) (*cf.ProjectConfiguration, erro
 		return nil, err
 	}
 	if config.Project == nil {
-		return nil, fmt.Errorf("Error: Set project first using 'project set <name>' or '-p <name>' option")
+		return nil, usageError{"Set project first using 'project set <name>' or '-p <name>' option"}
 	}
 
 	return config.Project, nil
@@ -619,111 +619,59 @@ func getTaskAPIErrorList(task *photon.Task) []photon.ApiError {
 	return apiErrorList
 }
 
-var taskInProgress *photon.Task
-var endAnimation bool
-
-// Display state of taskInProgress while endAnimation is not true
-// Print format:
-// e.g:  0h: 0m: 0s [  ] CREATE_HOST : QUEUED
-//       0h: 0m: 0s [= ] CREATE_HOST : CREATE_HOST | Step 1/1
-//       0h: 0m: 1s [==] CREATE_HOST : COMPLETED
-func displayTaskProgress(start time.Time) {
-	cursor := 0
-	displayInterval := 500 * time.Millisecond
-	for !endAnimation {
-		if taskInProgress != nil {
-			var taskStatus string
-			startedStep := findStartedStep(taskInProgress)
-
-			if startedStep == nil {
-				taskStatus = taskInProgress.State
-			} else {
-				cursor = startedStep.Sequence + 1
-				taskStatus = fmt.Sprintf("%s | Step %d/%d",
-					startedStep.Operation, startedStep.Sequence+1, len(taskInProgress.Steps))
-			}
-
-			fmt.Printf("\r%s\r", strings.Repeat(" ", 100))
-
-			elapsed := int(time.Since(start).Seconds())
-			fmt.Printf("%2dh%2dm%2ds ", elapsed/3600, (elapsed/60)%60, elapsed%60)
-			fmt.Printf("[%s] ", getProgressBar(cursor, len(taskInProgress.Steps)+1))
-			fmt.Printf("%s : %s", taskInProgress.Operation, taskStatus)
-		}
-		time.Sleep(displayInterval)
-	}
-	fmt.Printf("\r%s\r", strings.Repeat(" ", 100))
-}
-
 // Wait for task to finish and display task progress
 func pollTask(id string) (task *photon.Task, err error) {
-	return pollTaskWithTimeout(client.Esxclient, id, 30*time.Minute)
+	return pollTaskWithTimeout(client.Esxclient, id, defaultTaskTimeout)
 }
 
-func pollTaskWithTimeout(api *photon.Client, id string, pollTimeout time.Duration) (task *photon.Task, err error) {
-	start := time.Now()
-	numErr := 0
-
-	taskPollDelay := 500 * time.Millisecond
-	taskRetryCount := 3
-
-	endAnimation = false
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		displayTaskProgress(start)
-	}()
-
-	for time.Since(start) < pollTimeout {
-		task, err = api.Tasks.Get(id)
-
-		if err != nil {
-			switch err.(type) {
-			case photon.ApiError:
-				apiErrorList := getTaskAPIErrorList(task)
-				if len(apiErrorList) != 0 {
-					err = fmt.Errorf("%s\nAPI Errors: %s", err.Error(), apiErrorList)
-				}
-				endAnimation = true
-				wg.Wait()
-				return
-			default:
-				apiErrorList := getTaskAPIErrorList(task)
-				if len(apiErrorList) != 0 {
-					err = fmt.Errorf("%s\nAPI Errors: %s", err.Error(), apiErrorList)
-				}
+// Wait for task to finish and display task progress, for the given time unless a timeout
+// is given with --timeout or set for the target
+func pollTaskWithTimeout(api *photon.Client, id string, defaultTimeout time.Duration) (task *photon.Task, err error) {
+	options, err := getPollOptions(defaultTimeout, defaultTaskPollInterval)
+	if err != nil {
+		return nil, err
+	}
+	tracker := newTaskTracker(id)
+	renderer := newProgressRenderer(os.Stdout, []*taskTracker{tracker})
+	renderer.start()
+	defer renderer.stop()
+	return tracker.pollTaskUntilInterrupted(api, options)
+}
 
-				if task != nil && task.State == "ERROR" {
-					endAnimation = true
-					wg.Wait()
-					return
-				}
+// Wait for task to finish without displaying its progress, for scripts
+func waitTask(id string) (task *photon.Task, err error) {
+	return waitTaskWithTimeout(id, defaultTaskTimeout)
+}
 
-				numErr++
-				if numErr > taskRetryCount {
-					endAnimation = true
-					wg.Wait()
-					return
-				}
-			}
-		} else {
-			numErr = 0
-			taskInProgress = task
-			if task.State == "COMPLETED" {
-				endAnimation = true
-				wg.Wait()
-				return
-			}
-		}
+// Wait for task to finish without displaying its progress, for the given time unless a
+// timeout is given with --timeout or set for the target
+func waitTaskWithTimeout(id string, defaultTimeout time.Duration) (task *photon.Task, err error) {
+	options, err := getPollOptions(defaultTimeout, defaultTaskPollInterval)
+	if err != nil {
+		return nil, err
+	}
+	return newTaskTracker(id).pollTaskUntilInterrupted(client.Esxclient, options)
+}
 
-		time.Sleep(taskPollDelay)
+// Records a task started by the command in the audit log, then waits for it, unless
+// the command is run with --async, and records how it ended. Every command starting a
+// task goes through here, so that the task is audited once and by the command that
+// started it.
+func waitStartedTask(c *cli.Context, started *photon.Task, defaultTimeout time.Duration) (*photon.Task, error) {
+	auditTask(c, started.ID, started, nil)
+	if c.GlobalBool("async") {
+		return nil, startTaskOperation(started.ID, c)
 	}
 
-	endAnimation = true
-	wg.Wait()
-	err = fmt.Errorf("Timed out while waiting for task to complete")
-	return
+	var task *photon.Task
+	var err error
+	if c.GlobalIsSet("non-interactive") || utils.NeedsFormatting(c) {
+		task, err = waitTaskWithTimeout(started.ID, defaultTimeout)
+	} else {
+		task, err = pollTaskWithTimeout(client.Esxclient, started.ID, defaultTimeout)
+	}
+	auditTask(c, started.ID, task, err)
+	return task, err
 }
 
 func findStartedStep(task *photon.Task) *photon.Step {
@@ -739,26 +687,59 @@ func getProgressBar(cursor int, len int) string {
 	return strings.Repeat("=", cursor) + strings.Repeat(" ", len-cursor)
 }
 
-func waitOnTaskOperation(taskId string, c *cli.Context) (string, error) {
-	var task *photon.Task
-	var err error
-	needsFormatting := utils.NeedsFormatting(c)
-	if c.GlobalIsSet("non-interactive") || needsFormatting {
-		task, err = client.Esxclient.Tasks.Wait(taskId)
-		if err != nil {
-			return "", err
-		}
-		if !needsFormatting {
-			fmt.Println(task.Entity.ID)
-		}
+func waitOnTaskOperation(started *photon.Task, c *cli.Context) (string, error) {
+	task, err := waitStartedTask(c, started, defaultTaskTimeout)
+	if err != nil {
+		return "", err
+	}
+
+	if utils.NeedsFormatting(c) {
+		return task.Entity.ID, nil
+	}
+	if c.GlobalIsSet("non-interactive") {
+		fmt.Println(task.Entity.ID)
 	} else {
-		task, err = pollTask(taskId)
-		if err != nil {
-			return "", err
-		}
 		fmt.Printf("%s completed for '%s' entity %s\n", task.Operation, task.Entity.Kind, task.Entity.ID)
 	}
-	return task.Entity.ID, err
+	return task.Entity.ID, nil
+}
+
+// Shows the task started by a command run with --async, and returns errTaskStarted to end
+// the command without waiting for the task. The ID of the task is shown even if the task
+// cannot be read again, so that it can still be waited for.
+func startTaskOperation(taskId string, c *cli.Context) error {
+	needsFormatting := utils.NeedsFormatting(c)
+	if c.GlobalIsSet("non-interactive") && !needsFormatting {
+		fmt.Println(taskId)
+	}
+
+	task, err := client.Esxclient.Tasks.Get(taskId)
+	if err != nil {
+		task = &photon.Task{ID: taskId}
+	}
+
+	if needsFormatting {
+		utils.FormatObject(task, os.Stdout, c)
+	} else if c.GlobalIsSet("non-interactive") {
+		return errTaskStarted
+	} else if len(task.Operation) == 0 {
+		fmt.Printf("Task %s started\n", task.ID)
+		fmt.Printf("Wait for it with 'photon task wait %s'\n", task.ID)
+	} else {
+		fmt.Printf("%s started for '%s' entity %s as task %s\n", task.Operation, task.Entity.Kind, task.Entity.ID,
+			task.ID)
+		fmt.Printf("Wait for it with 'photon task wait %s'\n", task.ID)
+	}
+	return errTaskStarted
+}
+
+// Fails for the commands that wait for a task before starting the next one, which
+// cannot be run with --async
+func checkNotAsync(c *cli.Context, command string) error {
+	if c.GlobalBool("async") {
+		return usageError{fmt.Sprintf("%s waits for each of its tasks and cannot be run with --async", command)}
+	}
+	return nil
 }
 
 func getCommaSeparatedStringFromStringArray(arr []string) string {
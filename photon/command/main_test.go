@@ -0,0 +1,35 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+// Keeps the files written by commands, such as the config file, the key of the
+// credentials and the audit log, in a temporary directory instead of the config
+// directory of the user running the tests
+func TestMain(m *testing.M) {
+	configDir, err := ioutil.TempDir("", "command-test-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	cf.UserConfigDir = configDir
+
+	exitCode := m.Run()
+	os.RemoveAll(configDir)
+	os.Exit(exitCode)
+}
@@ -12,7 +12,6 @@ package command
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"regexp"
 	"text/tabwriter"
@@ -55,7 +54,7 @@ func GetNetworksCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createNetwork(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -65,7 +64,7 @@ func GetNetworksCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := deleteNetwork(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -81,7 +80,7 @@ func GetNetworksCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listNetworks(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -91,7 +90,7 @@ func GetNetworksCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := showNetwork(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -101,7 +100,7 @@ func GetNetworksCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := setDefaultNetwork(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -136,10 +135,10 @@ func createNetwork(c *cli.Context, w io.Writer) error {
 	}
 
 	if len(name) == 0 {
-		return fmt.Errorf("Please provide network name")
+		return usageError{"Please provide network name"}
 	}
 	if len(portGroups) == 0 {
-		return fmt.Errorf("Please provide portgroups")
+		return usageError{"Please provide portgroups"}
 	}
 
 	portGroupList := regexp.MustCompile(`\s*,\s*`).Split(portGroups, -1)
@@ -159,7 +158,7 @@ func createNetwork(c *cli.Context, w io.Writer) error {
 		return err
 	}
 
-	id, err := waitOnTaskOperation(task.ID, c)
+	id, err := waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
@@ -193,7 +192,7 @@ func deleteNetwork(c *cli.Context) error {
 	}
 
 	if confirmed(c.GlobalIsSet("non-interactive")) {
-		_, err = waitOnTaskOperation(task.ID, c)
+		_, err = waitOnTaskOperation(task, c)
 		if err != nil {
 			return err
 		}
@@ -300,7 +299,7 @@ func setDefaultNetwork(c *cli.Context, w io.Writer) error {
 	}
 
 	if confirmed(utils.IsNonInteractive(c)) {
-		id, err := waitOnTaskOperation(task.ID, c)
+		id, err := waitOnTaskOperation(task, c)
 		if err != nil {
 			return err
 		}
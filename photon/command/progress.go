@@ -0,0 +1,455 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware/photon-controller-go-sdk/photon"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	// Defaults of --timeout and --poll-interval
+	defaultTaskTimeout      = 30 * time.Minute
+	defaultTaskPollInterval = 500 * time.Millisecond
+	// Polls slow down up to this interval while tasks stay in the same step
+	maxAdaptivePollInterval = 10 * time.Second
+	taskRetryCount          = 3
+	// How often the progress of tasks is redrawn
+	progressDisplayInterval = 500 * time.Millisecond
+	// Width of the progress lines, which are cleared before being redrawn
+	progressLineWidth = 100
+)
+
+// Returned for the tasks that are no longer waited for because another task failed
+var errWaitCancelled = errors.New("Not waited for since another task failed")
+
+// How long to wait for a task, and how often to poll it at first
+type pollOptions struct {
+	timeout  time.Duration
+	interval time.Duration
+}
+
+// Returns the poll options given with --timeout and --poll-interval, or else set for
+// the target, or else the given defaults
+func getPollOptions(defaultTimeout time.Duration, defaultInterval time.Duration) (pollOptions, error) {
+	options := pollOptions{timeout: defaultTimeout, interval: defaultInterval}
+	config, err := loadTargetConfig()
+	if err != nil {
+		return options, err
+	}
+	// The TaskTimeout of the target replaces the usual default, but not the longer
+	// ones of slow operations such as deployments: only --timeout replaces those
+	taskTimeout := config.TaskTimeout
+	if defaultTimeout != defaultTaskTimeout {
+		taskTimeout = cf.ConfigOverrides.TaskTimeout
+	}
+	if len(taskTimeout) != 0 {
+		options.timeout, err = cf.ParseDurationSetting("TaskTimeout", taskTimeout)
+		if err != nil {
+			return options, err
+		}
+	}
+	if len(config.TaskPollInterval) != 0 {
+		options.interval, err = cf.ParseDurationSetting("TaskPollInterval", config.TaskPollInterval)
+		if err != nil {
+			return options, err
+		}
+	}
+	return options, nil
+}
+
+// Returns the interval before the next poll: polls slow down while nothing changes,
+// as long-running steps don't need to be polled as often
+func nextPollInterval(interval time.Duration, options pollOptions, changed bool) time.Duration {
+	if changed {
+		return options.interval
+	}
+	maxInterval := maxAdaptivePollInterval
+	if options.interval > maxInterval {
+		maxInterval = options.interval
+	}
+	interval = interval * 3 / 2
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+	return interval
+}
+
+// Follows the progress of one task, or of another operation such as a cluster
+// getting ready. It is updated by the goroutine polling the API, and read by the
+// renderer showing the progress.
+type taskTracker struct {
+	id    string
+	start time.Time
+
+	mutex     sync.Mutex
+	operation string
+	state     string
+	step      *photon.Step
+	stepCount int
+	done      bool
+	err       error
+}
+
+func newTaskTracker(id string) *taskTracker {
+	return &taskTracker{id: id, start: time.Now()}
+}
+
+// Updates the progress from the last state of the task, returns true if it changed
+func (tracker *taskTracker) update(task *photon.Task) bool {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	step := findStartedStep(task)
+	changed := tracker.state != task.State || (step == nil) != (tracker.step == nil) ||
+		(step != nil && step.Sequence != tracker.step.Sequence)
+	tracker.operation = task.Operation
+	tracker.state = task.State
+	tracker.step = step
+	tracker.stepCount = len(task.Steps)
+	return changed
+}
+
+// Updates the progress of an operation that is not a task, returns true if it changed
+func (tracker *taskTracker) updateState(operation string, state string) bool {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	changed := tracker.state != state
+	tracker.operation = operation
+	tracker.state = state
+	return changed
+}
+
+// Records the end of the wait, with its error if the task did not complete
+func (tracker *taskTracker) finish(err error) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.done = true
+	tracker.err = err
+	switch {
+	case err == errWaitCancelled:
+		tracker.state = "NOT WAITED"
+	case errors.As(err, new(photon.TaskTimeoutError)), errors.As(err, new(timeoutError)):
+		tracker.state = "TIMED OUT"
+	case err != nil && tracker.state != "ERROR":
+		tracker.state = "FAILED"
+	}
+	tracker.step = nil
+}
+
+// Returns the line showing the progress
+// e.g:  0h 0m 0s [  ] CREATE_HOST : QUEUED
+//       0h 0m 0s [= ] CREATE_HOST : CREATE_HOST | Step 1/1
+//       0h 0m 1s [==] CREATE_HOST : COMPLETED
+func (tracker *taskTracker) progressLine(now time.Time) string {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	if len(tracker.operation) == 0 && !tracker.done {
+		return ""
+	}
+	cursor := 0
+	status := tracker.state
+	if tracker.step != nil {
+		cursor = tracker.step.Sequence + 1
+		status = fmt.Sprintf("%s | Step %d/%d", tracker.step.Operation, tracker.step.Sequence+1, tracker.stepCount)
+	} else if tracker.state == "COMPLETED" {
+		cursor = tracker.stepCount + 1
+	}
+	operation := tracker.operation
+	if len(operation) == 0 {
+		operation = tracker.id
+	}
+
+	elapsed := int(now.Sub(tracker.start).Seconds())
+	line := fmt.Sprintf("%2dh%2dm%2ds [%s] %s : %s", elapsed/3600, (elapsed/60)%60, elapsed%60,
+		getProgressBar(cursor, tracker.stepCount+1), operation, status)
+	if len(line) > progressLineWidth {
+		line = line[:progressLineWidth]
+	}
+	return line
+}
+
+// Polls a task until it ends, fails or the timeout is reached, or until cancel is closed.
+// Errors getting the task are retried a few times before giving up.
+func (tracker *taskTracker) pollTask(api *photon.Client, options pollOptions,
+	cancel <-chan struct{}) (task *photon.Task, err error) {
+
+	defer func() { tracker.finish(err) }()
+	numErr := 0
+	interval := options.interval
+	for time.Since(tracker.start) < options.timeout {
+		task, err = api.Tasks.Get(tracker.id)
+		changed := false
+		if task != nil {
+			changed = tracker.update(task)
+		}
+
+		if err != nil {
+			apiErrorList := getTaskAPIErrorList(task)
+			if len(apiErrorList) != 0 {
+				err = fmt.Errorf("%w\nAPI Errors: %s", err, apiErrorList)
+			}
+			if errors.As(err, new(photon.ApiError)) || (task != nil && task.State == "ERROR") {
+				return
+			}
+			numErr++
+			if numErr > taskRetryCount {
+				return
+			}
+		} else {
+			numErr = 0
+			if task.State == "COMPLETED" {
+				return
+			}
+		}
+
+		interval = nextPollInterval(interval, options, changed)
+		select {
+		case <-cancel:
+			err = errWaitCancelled
+			return
+		case <-time.After(interval):
+		}
+	}
+
+	err = taskTimeoutError{photon.TaskTimeoutError{ID: tracker.id}, options.timeout}
+	return
+}
+
+// Polls a task like pollTask until Ctrl-C is pressed, which stops waiting with an
+// error telling how to resume
+func (tracker *taskTracker) pollTaskUntilInterrupted(api *photon.Client,
+	options pollOptions) (task *photon.Task, err error) {
+
+	interrupted, stopWatching := watchInterrupt()
+	defer stopWatching()
+	task, err = tracker.pollTask(api, options, interrupted)
+	if err == errWaitCancelled {
+		err = newTasksInterruptedError([]string{tracker.id})
+	}
+	return
+}
+
+// Watches for Ctrl-C while waiting: the returned channel is closed on the first one,
+// so that the progress can be cleared and the command can tell how to resume, and
+// the process exits at once on the second one. The returned function stops watching.
+func watchInterrupt() (<-chan struct{}, func()) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt)
+	interrupted := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			close(interrupted)
+		case <-stopped:
+			return
+		}
+		select {
+		case <-signals:
+			fmt.Fprintln(os.Stderr)
+			os.Exit(ExitInterrupted)
+		case <-stopped:
+		}
+	}()
+	return interrupted, func() {
+		signal.Stop(signals)
+		close(stopped)
+	}
+}
+
+// Tells if the progress is written to a terminal, which can redraw it in place. Tests
+// replace it to check the output of either mode.
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
+
+// Shows the progress of tasks, one line each, redrawing them in place until stopped.
+// When the output is not a terminal, e.g. a log file, escape sequences would only
+// clutter it: a plain line is written instead each time the progress of a task changes.
+type progressRenderer struct {
+	w        io.Writer
+	trackers []*taskTracker
+	terminal bool
+	// Number of lines drawn, to move back up to redraw them
+	lines int
+	// Last line written for each task without a terminal
+	written []string
+	stopped chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newProgressRenderer(w io.Writer, trackers []*taskTracker) *progressRenderer {
+	return &progressRenderer{
+		w:        w,
+		trackers: trackers,
+		terminal: isTerminal(w),
+		written:  make([]string, len(trackers)),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// Starts redrawing the progress in the background
+func (renderer *progressRenderer) start() {
+	renderer.wg.Add(1)
+	go func() {
+		defer renderer.wg.Done()
+		for {
+			renderer.render()
+			select {
+			case <-renderer.stopped:
+				return
+			case <-time.After(progressDisplayInterval):
+			}
+		}
+	}()
+}
+
+// Stops redrawing the progress and clears it, as the command then prints the result
+// of its tasks. Without a terminal, the last progress of each task is written instead.
+func (renderer *progressRenderer) stop() {
+	close(renderer.stopped)
+	renderer.wg.Wait()
+	if !renderer.terminal {
+		renderer.renderLines()
+		return
+	}
+	renderer.clear()
+}
+
+// Draws the current progress over the previous one
+func (renderer *progressRenderer) render() {
+	if !renderer.terminal {
+		renderer.renderLines()
+		return
+	}
+
+	now := time.Now()
+	if len(renderer.trackers) == 1 {
+		line := renderer.trackers[0].progressLine(now)
+		if len(line) != 0 {
+			renderer.clear()
+			fmt.Fprint(renderer.w, line)
+		}
+		return
+	}
+
+	var buf strings.Builder
+	if renderer.lines != 0 {
+		// Move back to the first line
+		fmt.Fprintf(&buf, "\033[%dA", renderer.lines)
+	}
+	for _, tracker := range renderer.trackers {
+		line := tracker.progressLine(now)
+		if len(line) == 0 {
+			line = fmt.Sprintf("%s : QUEUED", tracker.id)
+		}
+		fmt.Fprintf(&buf, "\r\033[2K%s\n", line)
+	}
+	renderer.lines = len(renderer.trackers)
+	fmt.Fprint(renderer.w, buf.String())
+}
+
+// Writes a line for each task whose progress changed since its last line. The elapsed
+// time, at the start of the line, changes every second and is not a change of progress.
+func (renderer *progressRenderer) renderLines() {
+	now := time.Now()
+	for i, tracker := range renderer.trackers {
+		line := tracker.progressLine(now)
+		if len(line) == 0 {
+			continue
+		}
+		progress := line[strings.Index(line, "["):]
+		if progress != renderer.written[i] {
+			renderer.written[i] = progress
+			fmt.Fprintln(renderer.w, line)
+		}
+	}
+}
+
+// Clears the progress, whether a single line or one line for each task
+func (renderer *progressRenderer) clear() {
+	if renderer.lines != 0 {
+		// Move back to the first line and clear it and the ones below
+		fmt.Fprintf(renderer.w, "\033[%dA\r\033[J", renderer.lines)
+		renderer.lines = 0
+		return
+	}
+	fmt.Fprintf(renderer.w, "\r%s\r", strings.Repeat(" ", progressLineWidth))
+}
+
+// Policies of waitForTasks when a task does not complete
+const (
+	// Wait for all the tasks to end
+	waitAll = iota
+	// Stop waiting for the other tasks
+	failFast
+)
+
+// Waits for several tasks at once, showing their progress to w if it is not nil,
+// until they end or interrupted is closed. Returns the tasks, nil when not found,
+// and the error of each one.
+func waitForTasks(api *photon.Client, ids []string, options pollOptions, policy int,
+	interrupted <-chan struct{}, w io.Writer) ([]*photon.Task, []error) {
+
+	trackers := make([]*taskTracker, len(ids))
+	for i, id := range ids {
+		trackers[i] = newTaskTracker(id)
+	}
+	var renderer *progressRenderer
+	if w != nil {
+		renderer = newProgressRenderer(w, trackers)
+		renderer.start()
+	}
+
+	tasks := make([]*photon.Task, len(ids))
+	errs := make([]error, len(ids))
+	cancel := make(chan struct{})
+	var cancelOnce sync.Once
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-interrupted:
+			cancelOnce.Do(func() { close(cancel) })
+		case <-done:
+		}
+	}()
+	for i, tracker := range trackers {
+		wg.Add(1)
+		go func(i int, tracker *taskTracker) {
+			defer wg.Done()
+			tasks[i], errs[i] = tracker.pollTask(api, options, cancel)
+			if errs[i] != nil && errs[i] != errWaitCancelled && policy == failFast {
+				cancelOnce.Do(func() { close(cancel) })
+			}
+		}(i, tracker)
+	}
+	wg.Wait()
+	close(done)
+
+	if renderer != nil {
+		renderer.stop()
+	}
+	return tasks, errs
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/photon-controller-go-sdk/photon"
+
+	cf "github.com/vmware/photon-controller-cli/photon/configuration"
+	"github.com/vmware/photon-controller-cli/photon/mocks"
+)
+
+func TestTaskTrackerProgressLine(t *testing.T) {
+	tracker := newTaskTracker("fake-task-id")
+	if line := tracker.progressLine(tracker.start); line != "" {
+		t.Errorf("Expected no progress before the task is known, got '%s'", line)
+	}
+
+	tracker.update(&photon.Task{
+		Operation: "CREATE_HOST",
+		State:     "STARTED",
+		Steps:     []photon.Step{{Operation: "CREATE_HOST", State: "STARTED", Sequence: 0}},
+	})
+	line := tracker.progressLine(tracker.start.Add(61 * time.Second))
+	if line != " 0h 1m 1s [= ] CREATE_HOST : CREATE_HOST | Step 1/1" {
+		t.Errorf("Unexpected progress line '%s'", line)
+	}
+
+	tracker.finish(photon.TaskTimeoutError{ID: "fake-task-id"})
+	line = tracker.progressLine(tracker.start)
+	if !strings.HasSuffix(line, "CREATE_HOST : TIMED OUT") {
+		t.Errorf("Expected the task to be shown as timed out, got '%s'", line)
+	}
+
+	tracker.finish(errWaitCancelled)
+	line = tracker.progressLine(tracker.start)
+	if !strings.HasSuffix(line, "CREATE_HOST : NOT WAITED") {
+		t.Errorf("Expected the task to be shown as not waited for, got '%s'", line)
+	}
+}
+
+func TestWaitForTasks(t *testing.T) {
+	server := mocks.NewTestServer()
+	defer server.Close()
+	for _, task := range []photon.Task{
+		{ID: "fake-failed-task-id", Operation: "CREATE_VM", State: "ERROR"},
+		{ID: "fake-started-task-id", Operation: "CREATE_VM", State: "STARTED"},
+		{ID: "fake-completed-task-id", Operation: "DELETE_VM", State: "COMPLETED"},
+	} {
+		response, err := json.Marshal(task)
+		if err != nil {
+			t.Error("Not expecting error serializing expected task")
+		}
+		mocks.RegisterResponder(
+			"GET",
+			server.URL+"/tasks/"+task.ID,
+			mocks.CreateResponder(200, string(response[:])))
+	}
+
+	mocks.Activate(true)
+	httpClient := &http.Client{Transport: mocks.DefaultMockTransport}
+	api := photon.NewTestClient(server.URL, nil, httpClient)
+
+	defer func(previous func(io.Writer) bool) { isTerminal = previous }(isTerminal)
+	isTerminal = func(io.Writer) bool { return true }
+
+	var buf bytes.Buffer
+	ids := []string{"fake-started-task-id", "fake-failed-task-id", "fake-completed-task-id"}
+	tasks, errs := waitForTasks(api, ids, pollOptions{time.Minute, time.Millisecond}, failFast, nil, &buf)
+	if errs[0] != errWaitCancelled || tasks[0].State != "STARTED" {
+		t.Errorf("Expected the started task not to be waited for, got %v", errs[0])
+	}
+	if classifyError(errs[1]).ExitCode != ExitTaskError {
+		t.Errorf("Expected a task error for the failed task, got %v", errs[1])
+	}
+	if errs[2] != nil || tasks[2].State != "COMPLETED" {
+		t.Errorf("Expected the completed task, got %v", errs[2])
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "\r\033[2K") || !strings.HasSuffix(output, "\033[3A\r\033[J") {
+		t.Errorf("Expected the progress of the 3 tasks to be cleared at the end, got %q", output)
+	}
+
+	_, errs = waitForTasks(api, []string{"fake-failed-task-id", "fake-completed-task-id"},
+		pollOptions{time.Minute, time.Millisecond}, waitAll, nil, nil)
+	if errs[0] == nil || errs[1] != nil {
+		t.Errorf("Expected only the failed task to fail, got %v", errs)
+	}
+}
+
+func TestProgressRendererWithoutTerminal(t *testing.T) {
+	tracker := newTaskTracker("fake-task-id")
+	tracker.update(&photon.Task{Operation: "CREATE_VM", State: "STARTED"})
+
+	var buf bytes.Buffer
+	renderer := newProgressRenderer(&buf, []*taskTracker{tracker})
+	renderer.render()
+	renderer.render()
+	tracker.update(&photon.Task{Operation: "CREATE_VM", State: "COMPLETED"})
+	renderer.render()
+	renderer.render()
+
+	output := buf.String()
+	if strings.Contains(output, "\033") || strings.Contains(output, "\r") {
+		t.Errorf("Expected no escape sequences without a terminal, got %q", output)
+	}
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "CREATE_VM : STARTED") ||
+		!strings.HasSuffix(lines[1], "CREATE_VM : COMPLETED") {
+		t.Errorf("Expected one line for each change of progress, got %q", output)
+	}
+}
+
+func TestPollTaskUntilInterrupted(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Interrupts can't be sent to a process on Windows")
+	}
+	server := mocks.NewTestServer()
+	defer server.Close()
+	response, err := json.Marshal(photon.Task{ID: "fake-task-id", Operation: "CREATE_VM", State: "STARTED"})
+	if err != nil {
+		t.Error("Not expecting error serializing expected task")
+	}
+	mocks.RegisterResponder(
+		"GET",
+		server.URL+"/tasks/fake-task-id",
+		mocks.CreateResponder(200, string(response[:])))
+
+	mocks.Activate(true)
+	httpClient := &http.Client{Transport: mocks.DefaultMockTransport}
+	api := photon.NewTestClient(server.URL, nil, httpClient)
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal("Not expecting error finding the test process")
+	}
+	time.AfterFunc(100*time.Millisecond, func() { process.Signal(os.Interrupt) })
+	tracker := newTaskTracker("fake-task-id")
+	task, err := tracker.pollTaskUntilInterrupted(api, pollOptions{time.Second, 10 * time.Millisecond})
+	if task == nil || task.State != "STARTED" {
+		t.Errorf("Expected the last state of the task, got %v", task)
+	}
+	if classifyError(err).ExitCode != ExitInterrupted ||
+		err.Error() != "task fake-task-id still running; resume with `photon task monitor fake-task-id`" {
+		t.Errorf("Expected waiting to be interrupted, got %v", err)
+	}
+
+	interrupted := make(chan struct{})
+	close(interrupted)
+	_, errs := waitForTasks(api, []string{"fake-task-id"}, pollOptions{time.Second, 10 * time.Millisecond},
+		waitAll, interrupted, nil)
+	if errs[0] != errWaitCancelled {
+		t.Errorf("Expected the task not to be waited for once interrupted, got %v", errs[0])
+	}
+}
+
+func TestNextPollInterval(t *testing.T) {
+	options := pollOptions{timeout: time.Minute, interval: 2 * time.Second}
+	interval := nextPollInterval(options.interval, options, false)
+	if interval != 3*time.Second {
+		t.Errorf("Expected polls to slow down while nothing changes, got %s", interval)
+	}
+	for i := 0; i < 10; i++ {
+		interval = nextPollInterval(interval, options, false)
+	}
+	if interval != maxAdaptivePollInterval {
+		t.Errorf("Expected polls to slow down to %s at most, got %s", maxAdaptivePollInterval, interval)
+	}
+	interval = nextPollInterval(interval, options, true)
+	if interval != options.interval {
+		t.Errorf("Expected polls to speed up again once the task changes, got %s", interval)
+	}
+}
+
+func TestGetPollOptions(t *testing.T) {
+	configOri, err := cf.LoadConfig()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+	defer cf.SaveConfig(configOri)
+
+	err = cf.SaveConfig(&cf.Configuration{TaskTimeout: "45m"})
+	if err != nil {
+		t.Error("Not expecting error saving config file")
+	}
+
+	options, err := getPollOptions(defaultTaskTimeout, defaultTaskPollInterval)
+	if err != nil || options.timeout != 45*time.Minute {
+		t.Errorf("Expected the TaskTimeout of the target, got %v, %s", options.timeout, err)
+	}
+	options, err = getPollOptions(120*time.Minute, defaultTaskPollInterval)
+	if err != nil || options.timeout != 120*time.Minute {
+		t.Errorf("Expected the longer default to be kept, got %v, %s", options.timeout, err)
+	}
+
+	cf.ConfigOverrides.TaskTimeout = "10m"
+	defer func() { cf.ConfigOverrides.TaskTimeout = "" }()
+	options, err = getPollOptions(120*time.Minute, defaultTaskPollInterval)
+	if err != nil || options.timeout != 10*time.Minute {
+		t.Errorf("Expected --timeout to replace the longer default, got %v, %s", options.timeout, err)
+	}
+}
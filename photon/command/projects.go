@@ -12,7 +12,6 @@ package command
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"regexp"
 	"strings"
@@ -71,7 +70,7 @@ func GetProjectsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createProject(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -81,7 +80,7 @@ func GetProjectsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := deleteProject(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -91,7 +90,7 @@ func GetProjectsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := showProject(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -101,7 +100,7 @@ func GetProjectsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getProject(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -111,7 +110,7 @@ func GetProjectsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := setProject(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -127,7 +126,7 @@ func GetProjectsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listProjects(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -147,7 +146,7 @@ func GetProjectsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getProjectTasks(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -157,7 +156,7 @@ func GetProjectsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := setSecurityGroupsForProject(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -192,7 +191,7 @@ func createProject(c *cli.Context, w io.Writer) error {
 
 	var limitsList []photon.QuotaLineItem
 	if c.IsSet("limits") && c.IsSet("percent") {
-		return fmt.Errorf("Error: Can only specify one of '--limits' or '--percent'")
+		return usageError{"Can only specify one of '--limits' or '--percent'"}
 	}
 	if c.IsSet("limits") {
 		limitsList, err = parseLimitsListFromFlag(limits)
@@ -242,7 +241,7 @@ func createProject(c *cli.Context, w io.Writer) error {
 			return err
 		}
 
-		id, err := waitOnTaskOperation(createTask.ID, c)
+		id, err := waitOnTaskOperation(createTask, c)
 		if err != nil {
 			return err
 		}
@@ -279,7 +278,7 @@ func deleteProject(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	_, err = waitOnTaskOperation(deleteTask.ID, c)
+	_, err = waitOnTaskOperation(deleteTask, c)
 	if err != nil {
 		return err
 	}
@@ -366,7 +365,7 @@ func getProject(c *cli.Context, w io.Writer) error {
 
 	project := config.Project
 	if project == nil {
-		return fmt.Errorf("Error: No Project selected\n")
+		return fmt.Errorf("No project selected")
 	}
 
 	if c.GlobalIsSet("non-interactive") {
@@ -399,7 +398,7 @@ func setProject(c *cli.Context) error {
 	}
 
 	if config == nil || config.Tenant == nil {
-		return fmt.Errorf("Error: Set tenant first using 'tenant set <name>' or '-t <name>' option")
+		return usageError{"Set tenant first using 'tenant set <name>' or '-t <name>' option"}
 	}
 
 	project, err := findProject(config.Tenant.ID, name)
@@ -407,8 +406,10 @@ func setProject(c *cli.Context) error {
 		return err
 	}
 
-	config.Project = &cf.ProjectConfiguration{Name: project.Name, ID: project.ID}
-	err = cf.SaveConfig(config)
+	err = cf.UpdateConfig(func(config *cf.Configuration) error {
+		config.Project = &cf.ProjectConfiguration{Name: project.Name, ID: project.ID}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
@@ -534,7 +535,7 @@ func setSecurityGroupsForProject(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
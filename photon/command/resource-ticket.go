@@ -12,7 +12,6 @@ package command
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"text/tabwriter"
 
@@ -53,7 +52,7 @@ func GetResourceTicketCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createResourceTicket(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -69,7 +68,7 @@ func GetResourceTicketCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := showResourceTicket(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -85,7 +84,7 @@ func GetResourceTicketCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listResourceTickets(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -105,7 +104,7 @@ func GetResourceTicketCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getResourceTicketTasks(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -170,7 +169,7 @@ func createResourceTicket(c *cli.Context, w io.Writer) error {
 			return err
 		}
 
-		_, err = waitOnTaskOperation(createTask.ID, c)
+		_, err = waitOnTaskOperation(createTask, c)
 		if err != nil {
 			return err
 		}
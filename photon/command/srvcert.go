@@ -10,55 +10,119 @@
 package command
 
 import (
-	"crypto/tls"
+	"crypto/sha256"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/vmware/photon-controller-cli/photon/client"
 	cf "github.com/vmware/photon-controller-cli/photon/configuration"
 )
 
+var fingerprintRegexp = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// Tells if the certificate of the server is trusted, connecting to it like the client
+// does, through the proxy of the target and with its client certificate
 func isServerTrusted(server string) (bool, error) {
-	bServerTrusted := false
+	targetConfig, err := loadTargetConfig()
+	if err != nil {
+		return false, err
+	}
+	roots, err := client.GetRootCAs(targetConfig)
+	if err != nil {
+		return false, err
+	}
+	transport, err := client.NewHTTPTransport(targetConfig, roots, false)
+	if err != nil {
+		return false, err
+	}
 
-	roots, err := cf.GetCertsFromLocalStore()
+	//Try connecting securely to the server
+	_, err = getPeerCertificate(transport, server)
+	if errors.As(err, new(x509.UnknownAuthorityError)) {
+		return false, nil
+	}
+	return err == nil, err
+}
 
+func getServerCert(server string) (*x509.Certificate, error) {
+	targetConfig, err := loadTargetConfig()
 	if err != nil {
-		return bServerTrusted, err
+		return nil, err
+	}
+	transport, err := client.NewHTTPTransport(targetConfig, nil, true)
+	if err != nil {
+		return nil, err
 	}
 
-	//Try connecting securely to the server
-	config := tls.Config{RootCAs: roots, InsecureSkipVerify: false}
-	conn, err := tls.Dial("tcp", server, &config)
+	cert, err := getPeerCertificate(transport, server)
+	if err != nil {
+		fmt.Println(err)
+		return nil, err
+	}
+	return cert, nil
+}
 
-	if err == nil {
-		bServerTrusted = true
-		_ = conn.Close()
-	} else {
-		switch err.(type) {
-		case x509.UnknownAuthorityError:
-			bServerTrusted = false
-			err = nil
-		}
+// Connects to the server with the transport and returns its certificate (the leaf
+// certificate). Any HTTP response will do, only the TLS handshake matters.
+func getPeerCertificate(transport *http.Transport, server string) (*x509.Certificate, error) {
+	defer transport.CloseIdleConnections()
+	httpClient := &http.Client{Transport: transport, Timeout: 30 * time.Second}
+	res, err := httpClient.Head("https://" + server)
+	if err != nil {
+		return nil, err
 	}
+	res.Body.Close()
+	if res.TLS == nil || len(res.TLS.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("No certificate presented by %s", server)
+	}
+	return res.TLS.PeerCertificates[0], nil
+}
 
-	return bServerTrusted, err
+// Returns the configuration of the target, with the CA file and client certificate
+// to use when checking whether the server is trusted
+func loadTargetConfig() (*cf.Configuration, error) {
+	config, err := cf.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cf.ApplyOverrides(config), nil
 }
 
-func getServerCert(server string) (*x509.Certificate, error) {
-	config := tls.Config{InsecureSkipVerify: true}
-	conn, err := tls.Dial("tcp", server, &config)
+// Returns the SHA-256 fingerprint of a certificate, as sha256:<hex>
+func certFingerprint(cert *x509.Certificate) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(cert.Raw))
+}
 
-	//Ensure we can connect to the server
-	if err == nil {
-		cert := new(x509.Certificate)
-		state := conn.ConnectionState()
-		//return 1st in the cert list (leaf cert)
-		if state.PeerCertificates != nil {
-			cert = state.PeerCertificates[0]
+// Parses a SHA-256 fingerprint given by the user, see normalizeFingerprint
+func parseFingerprint(fingerprint string) (string, error) {
+	normalized := normalizeFingerprint(fingerprint)
+	if !fingerprintRegexp.MatchString(normalized) {
+		return "", usageError{fmt.Sprintf("Invalid fingerprint '%s', should be sha256: followed by 64 hex digits", fingerprint)}
+	}
+	return normalized, nil
+}
+
+// Tells if the SHA-256 fingerprint of a certificate is one of the given normalized fingerprints
+func matchesFingerprint(cert *x509.Certificate, fingerprints []string) bool {
+	fingerprint := certFingerprint(cert)
+	for _, f := range fingerprints {
+		if f == fingerprint {
+			return true
 		}
-		_ = conn.Close()
-		return cert, nil
 	}
-	fmt.Println(err)
-	return nil, err
+	return false
+}
+
+// Normalizes a SHA-256 fingerprint given by the user: the sha256: prefix is optional,
+// and the hex digits can be in upper case and separated by colons, as shown by openssl
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.ToLower(strings.TrimSpace(fingerprint))
+	fingerprint = strings.TrimPrefix(fingerprint, "sha256:")
+	fingerprint = strings.Replace(fingerprint, ":", "", -1)
+	return "sha256:" + fingerprint
 }
@@ -16,12 +16,15 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -131,3 +134,54 @@ func TestServerTrustUtils(t *testing.T) {
 		t.Error("Failed to Add server cert to local store")
 	}
 }
+
+func TestTrustFingerprint(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "srvcert-test-")
+	if err != nil {
+		t.Fatal("Not expecting error creating a temporary directory")
+	}
+	defer os.RemoveAll(configDir)
+	defer func(previousDir string) { cf.UserConfigDir = previousDir }(cf.UserConfigDir)
+	cf.UserConfigDir = configDir
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Not expecting error parsing the server URL")
+	}
+	fingerprint := certFingerprint(server.Certificate())
+
+	_, err = parseFingerprint("md5:1234")
+	if err == nil {
+		t.Error("Expected error parsing an MD5 fingerprint")
+	}
+	opensslFingerprint := ""
+	for i := len("sha256:"); i < len(fingerprint); i += 2 {
+		opensslFingerprint += strings.ToUpper(fingerprint[i:i+2]) + ":"
+	}
+	parsed, err := parseFingerprint(strings.TrimSuffix(opensslFingerprint, ":"))
+	if err != nil || parsed != fingerprint {
+		t.Errorf("Expected %s parsing the fingerprint printed by openssl, got %s", fingerprint, parsed)
+	}
+
+	err = setupApiServerCert(u.Host, true, nil)
+	if err == nil {
+		t.Error("Expected error without a fingerprint in non-interactive mode")
+	}
+
+	wrongFingerprint := "sha256:" + strings.Repeat("0", 64)
+	err = setupApiServerCert(u.Host, true, []string{wrongFingerprint})
+	if err == nil || !strings.Contains(err.Error(), fingerprint) {
+		t.Errorf("Expected error showing the fingerprint of the server certificate, got %v", err)
+	}
+
+	err = setupApiServerCert(u.Host, true, []string{wrongFingerprint, fingerprint})
+	if err != nil {
+		t.Errorf("Not expecting error with the fingerprint of the server certificate: %s", err)
+	}
+	trusted, err := isServerTrusted(u.Host)
+	if err != nil || !trusted {
+		t.Errorf("Expected the server to be trusted after pinning its certificate, got %v", err)
+	}
+}
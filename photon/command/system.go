@@ -13,7 +13,6 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"log"
 	"net"
 	"os"
 	"regexp"
@@ -41,7 +40,7 @@ func GetSystemCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getStatus(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -51,7 +50,7 @@ func GetSystemCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := deploy(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -61,7 +60,7 @@ func GetSystemCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := addHosts(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -71,7 +70,7 @@ func GetSystemCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := destroy(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -85,7 +84,7 @@ func GetSystemCommand() cli.Command {
 						Action: func(c *cli.Context) {
 							err := deploymentMigrationPrepareDeprecated(c)
 							if err != nil {
-								log.Fatal("Error: ", err)
+								exitWithError(c, err)
 							}
 						},
 					},
@@ -95,7 +94,7 @@ func GetSystemCommand() cli.Command {
 						Action: func(c *cli.Context) {
 							err := deploymentMigrationFinalizeDeprecated(c)
 							if err != nil {
-								log.Fatal("Error: ", err)
+								exitWithError(c, err)
 							}
 						},
 					},
@@ -105,7 +104,7 @@ func GetSystemCommand() cli.Command {
 						Action: func(c *cli.Context) {
 							err := showMigrationStatusDeprecated(c)
 							if err != nil {
-								log.Fatal("Error: ", err)
+								exitWithError(c, err)
 							}
 						},
 					},
@@ -162,6 +161,10 @@ func deploy(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	err = checkNotAsync(c, "system deploy")
+	if err != nil {
+		return err
+	}
 	file := c.Args().First()
 	dcMap, err := manifest.LoadInstallation(file)
 	if err != nil {
@@ -173,19 +176,19 @@ func deploy(c *cli.Context) error {
 		return err
 	}
 
-	deploymentID, err := createDeploymentFromDcMap(dcMap)
+	deploymentID, err := createDeploymentFromDcMap(c, dcMap)
 	if err != nil {
 		return err
 	}
 
 	// Create Hosts
-	err = createHostsFromDcMap(dcMap, deploymentID)
+	err = createHostsFromDcMap(c, dcMap, deploymentID)
 	if err != nil {
 		return err
 	}
 
 	// Deploy
-	err = doDeploy(dcMap, deploymentID)
+	err = doDeploy(c, dcMap, deploymentID)
 	if err != nil {
 		return err
 	}
@@ -199,6 +202,10 @@ func addHosts(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	err = checkNotAsync(c, "system addHosts")
+	if err != nil {
+		return err
+	}
 	file := c.Args().First()
 	dcMap, err := manifest.LoadInstallation(file)
 	if err != nil {
@@ -214,7 +221,7 @@ func addHosts(c *cli.Context) error {
 	deploymentID := deployments.Items[0].ID
 
 	// Create Hosts
-	err = createHostsInBatch(dcMap, deploymentID)
+	err = createHostsInBatch(c, dcMap, deploymentID)
 	if err != nil {
 		return err
 	}
@@ -228,6 +235,10 @@ func destroy(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	err = checkNotAsync(c, "system destroy")
+	if err != nil {
+		return err
+	}
 
 	client.Esxclient, err = client.GetClient(false)
 	if err != nil {
@@ -238,7 +249,7 @@ func destroy(c *cli.Context) error {
 
 	// Destroy deployment
 	for _, deployment := range deployments.Items {
-		err = doDestroy(deployment.ID)
+		err = doDestroy(c, deployment.ID)
 		if err != nil {
 			return err
 		}
@@ -257,7 +268,7 @@ func destroy(c *cli.Context) error {
 				return err
 			}
 
-			deleteTask, err = pollTask(deleteTask.ID)
+			deleteTask, err = waitStartedTask(c, deleteTask, defaultTaskTimeout)
 			if err != nil {
 				return err
 			}
@@ -276,7 +287,7 @@ func destroy(c *cli.Context) error {
 			return err
 		}
 
-		deleteTask, err = pollTask(deleteTask.ID)
+		deleteTask, err = waitStartedTask(c, deleteTask, defaultTaskTimeout)
 		if err != nil {
 			return err
 		}
@@ -290,7 +301,7 @@ func destroy(c *cli.Context) error {
 			return err
 		}
 
-		task, err := pollTask(deleteTask.ID)
+		task, err := waitStartedTask(c, deleteTask, defaultTaskTimeout)
 		if err != nil {
 			return err
 		}
@@ -306,6 +317,10 @@ func deploymentMigrationPrepareDeprecated(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	err = checkNotAsync(c, "system migration prepare")
+	if err != nil {
+		return err
+	}
 	sourceAddress := c.Args().First()
 	client.Esxclient, err = client.GetClient(c.GlobalIsSet("non-interactive"))
 	if err != nil {
@@ -324,7 +339,7 @@ func deploymentMigrationPrepareDeprecated(c *cli.Context) error {
 		if err != nil {
 			return err
 		}
-		_, err = pollTask(initializeMigrate.ID)
+		_, err = waitStartedTask(c, initializeMigrate, defaultTaskTimeout)
 		if err != nil {
 			return err
 		}
@@ -343,6 +358,10 @@ func deploymentMigrationFinalizeDeprecated(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	err = checkNotAsync(c, "system migration finalize")
+	if err != nil {
+		return err
+	}
 	sourceAddress := c.Args().First()
 	client.Esxclient, err = client.GetClient(c.GlobalIsSet("non-interactive"))
 	if err != nil {
@@ -361,7 +380,7 @@ func deploymentMigrationFinalizeDeprecated(c *cli.Context) error {
 		if err != nil {
 			return err
 		}
-		_, err = pollTask(finalizeMigrate.ID)
+		_, err = waitStartedTask(c, finalizeMigrate, defaultTaskTimeout)
 		if err != nil {
 			return err
 		}
@@ -406,7 +425,7 @@ func showMigrationStatusDeprecated(c *cli.Context) error {
 	return nil
 }
 
-func createDeploymentFromDcMap(dcMap *manifest.Installation) (deploymentID string, err error) {
+func createDeploymentFromDcMap(c *cli.Context, dcMap *manifest.Installation) (deploymentID string, err error) {
 	err = validateDeploymentArguments(
 		dcMap.Deployment.ImageDatastores, dcMap.Deployment.AuthEnabled,
 		dcMap.Deployment.AuthTenant, dcMap.Deployment.AuthUsername, dcMap.Deployment.AuthPassword,
@@ -485,7 +504,7 @@ func createDeploymentFromDcMap(dcMap *manifest.Installation) (deploymentID strin
 		return "", err
 	}
 
-	task, err := pollTask(createDeploymentTask.ID)
+	task, err := waitStartedTask(c, createDeploymentTask, defaultTaskTimeout)
 	if err != nil {
 		return "", err
 	}
@@ -502,7 +521,7 @@ func contains(list []string, value string) bool {
 	return false
 }
 
-func createAvailabilityZonesFromDcMap(dcMap *manifest.Installation) (map[string]string, error) {
+func createAvailabilityZonesFromDcMap(c *cli.Context, dcMap *manifest.Installation) (map[string]string, error) {
 	availabilityZoneNameToIdMap := make(map[string]string)
 	for _, host := range dcMap.Hosts {
 		if len(host.AvailabilityZone) > 0 {
@@ -516,7 +535,7 @@ func createAvailabilityZonesFromDcMap(dcMap *manifest.Installation) (map[string]
 					return nil, err
 				}
 
-				task, err := pollTask(createAvailabilityZoneTask.ID)
+				task, err := waitStartedTask(c, createAvailabilityZoneTask, defaultTaskTimeout)
 				if err != nil {
 					return nil, err
 				}
@@ -527,8 +546,8 @@ func createAvailabilityZonesFromDcMap(dcMap *manifest.Installation) (map[string]
 	return availabilityZoneNameToIdMap, nil
 }
 
-func createHostsFromDcMap(dcMap *manifest.Installation, deploymentID string) error {
-	hostSpecs, err := createHostSpecs(dcMap)
+func createHostsFromDcMap(c *cli.Context, dcMap *manifest.Installation, deploymentID string) error {
+	hostSpecs, err := createHostSpecs(c, dcMap)
 	if err != nil {
 		return err
 	}
@@ -539,7 +558,7 @@ func createHostsFromDcMap(dcMap *manifest.Installation, deploymentID string) err
 			return err
 		}
 
-		task, err := pollTask(createHostTask.ID)
+		task, err := waitStartedTask(c, createHostTask, defaultTaskTimeout)
 		if err != nil {
 			return err
 		}
@@ -549,8 +568,8 @@ func createHostsFromDcMap(dcMap *manifest.Installation, deploymentID string) err
 	return nil
 }
 
-func createHostsInBatch(dcMap *manifest.Installation, deploymentID string) error {
-	hostSpecs, err := createHostSpecs(dcMap)
+func createHostsInBatch(c *cli.Context, dcMap *manifest.Installation, deploymentID string) error {
+	hostSpecs, err := createHostSpecs(c, dcMap)
 	if err != nil {
 		return err
 	}
@@ -570,11 +589,11 @@ func createHostsInBatch(dcMap *manifest.Installation, deploymentID string) error
 	}
 
 	for address, createTask := range createTaskMap {
-		task, err := pollTask(createTask.ID)
+		task, err := waitStartedTask(c, createTask, defaultTaskTimeout)
 		if err != nil {
 			pollErrors = append(pollErrors, err)
 			fmt.Printf("Creation of Host with ip '%s' failed: ID = %s with err '%s'\n\n",
-				address, task.ID, err)
+				address, createTask.ID, err)
 		} else {
 			fmt.Printf("Host with ip '%s' created: ID = %s\n\n", address, task.Entity.ID)
 		}
@@ -582,8 +601,8 @@ func createHostsInBatch(dcMap *manifest.Installation, deploymentID string) error
 	return nil
 }
 
-func createHostSpecs(dcMap *manifest.Installation) ([]photon.HostCreateSpec, error) {
-	availabilityZoneNameToIdMap, err := createAvailabilityZonesFromDcMap(dcMap)
+func createHostSpecs(c *cli.Context, dcMap *manifest.Installation) ([]photon.HostCreateSpec, error) {
+	availabilityZoneNameToIdMap, err := createAvailabilityZonesFromDcMap(c, dcMap)
 	if err != nil {
 		return nil, err
 	}
@@ -672,7 +691,7 @@ func inc(ip net.IP) {
 	}
 }
 
-func doDeploy(installSpec *manifest.Installation, deploymentID string) error {
+func doDeploy(c *cli.Context, installSpec *manifest.Installation, deploymentID string) error {
 	var desiredState string
 	if installSpec.Deployment.ResumeSystem {
 		desiredState = "READY"
@@ -687,7 +706,7 @@ func doDeploy(installSpec *manifest.Installation, deploymentID string) error {
 		return err
 	}
 
-	_, err = pollTaskWithTimeout(client.Esxclient, deployTask.ID, 120*time.Minute)
+	_, err = waitStartedTask(c, deployTask, 120*time.Minute)
 	if err != nil {
 		return err
 	}
@@ -696,13 +715,13 @@ func doDeploy(installSpec *manifest.Installation, deploymentID string) error {
 	return nil
 }
 
-func doDestroy(deploymentID string) error {
+func doDestroy(c *cli.Context, deploymentID string) error {
 	destroyTask, err := client.Esxclient.Deployments.Destroy(deploymentID)
 	if err != nil {
 		return err
 	}
 
-	_, err = pollTask(destroyTask.ID)
+	_, err = waitStartedTask(c, destroyTask, defaultTaskTimeout)
 	if err != nil {
 		return err
 	}
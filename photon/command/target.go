@@ -10,18 +10,20 @@
 package command
 
 import (
-	"crypto/md5"
-	"crypto/sha1"
 	"fmt"
-	"log"
+	"io"
 	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/codegangsta/cli"
 
 	"crypto/x509"
 	"github.com/vmware/photon-controller-cli/photon/client"
 	cf "github.com/vmware/photon-controller-cli/photon/configuration"
-	"github.com/vmware/photon-controller-go-sdk/photon/lightwave"
+	"github.com/vmware/photon-controller-cli/photon/utils"
 
 	"golang.org/x/crypto/ssh/terminal"
 )
@@ -31,6 +33,9 @@ import (
 //              login;  Usage: target login <token>
 //              logout; Usage: target logout
 //              show;   Usage: target show
+//              use;    Usage: target use <name>
+//              list;   Usage: target list
+//              delete; Usage: target delete <name>
 func GetTargetCommand() cli.Command {
 	command := cli.Command{
 		Name:  "target",
@@ -44,11 +49,36 @@ func GetTargetCommand() cli.Command {
 						Name:  "nocertcheck, c",
 						Usage: "flag to avoid validating server cert",
 					},
+					cli.StringFlag{
+						Name:  "name",
+						Usage: "name of the context to create or update, which becomes the current context",
+					},
+					cli.StringFlag{
+						Name:  "proxy",
+						Usage: "proxy URL for this target, instead of the one given by HTTPS_PROXY and NO_PROXY",
+					},
+					cli.StringFlag{
+						Name:  "ca-file",
+						Usage: "PEM file with certificate authorities to trust for this target, in addition to the system ones",
+					},
+					cli.StringFlag{
+						Name:  "client-cert",
+						Usage: "PEM file with a client certificate to present to this target",
+					},
+					cli.StringFlag{
+						Name:  "client-key",
+						Usage: "PEM file with the private key of the client certificate, if not in the certificate file",
+					},
+					cli.StringSliceFlag{
+						Name: "trust-fingerprint",
+						Usage: "trust the server certificate with this SHA-256 fingerprint (sha256:<hex>) " +
+							"without prompting, repeat for the API and authentication servers",
+					},
 				},
 				Action: func(c *cli.Context) {
 					err := setEndpoint(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -58,7 +88,37 @@ func GetTargetCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := showEndpoint(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "use",
+				Usage: "Switch to the target context with the specified name",
+				Action: func(c *cli.Context) {
+					err := useContext(c)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "List all target contexts",
+				Action: func(c *cli.Context) {
+					err := listContexts(c, os.Stdout)
+					if err != nil {
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "delete",
+				Usage: "Delete the target context with the specified name",
+				Action: func(c *cli.Context) {
+					err := deleteContext(c)
+					if err != nil {
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -82,7 +142,7 @@ func GetTargetCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := login(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -92,7 +152,7 @@ func GetTargetCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := logout(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -110,21 +170,60 @@ func setEndpoint(c *cli.Context) error {
 	}
 	endpoint := c.Args()[0]
 	noCertCheck := c.Bool("nocertcheck")
+	name := c.String("name")
+	fingerprints := c.StringSlice("trust-fingerprint")
 
-	config, err := cf.LoadConfig()
-	if err != nil {
-		return err
+	if noCertCheck && len(fingerprints) != 0 {
+		return usageError{"Cannot use --nocertcheck with --trust-fingerprint"}
+	}
+	for i, fingerprint := range fingerprints {
+		fingerprints[i], err = parseFingerprint(fingerprint)
+		if err != nil {
+			return err
+		}
 	}
 
-	config.CloudTarget = endpoint
-	config.IgnoreCertificate = noCertCheck
+	if len(name) != 0 {
+		err = cf.ValidateContextName(name)
+		if err != nil {
+			return err
+		}
+		if len(cf.ContextOverride) != 0 && cf.ContextOverride != name {
+			return usageError{fmt.Sprintf("Cannot use --context '%s' with target set --name '%s'", cf.ContextOverride, name)}
+		}
 
-	err = cf.SaveConfig(config)
+		err = cf.UpdateContexts(func(contexts *cf.ContextsConfiguration) error {
+			if _, ok := contexts.Contexts[name]; !ok {
+				contexts.Contexts[name] = &cf.Configuration{}
+			}
+			contexts.CurrentContext = name
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = cf.UpdateConfig(func(config *cf.Configuration) error {
+		config.CloudTarget = endpoint
+		config.IgnoreCertificate = noCertCheck
+		if c.IsSet("proxy") {
+			config.Proxy = c.String("proxy")
+		}
+		if c.IsSet("ca-file") {
+			config.CAFile = absolutePath(c.String("ca-file"))
+		}
+		if c.IsSet("client-cert") {
+			config.ClientCertFile = absolutePath(c.String("client-cert"))
+			config.ClientKeyFile = absolutePath(c.String("client-key"))
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	err = configureServerCerts(endpoint, noCertCheck, c.GlobalIsSet("non-interactive"))
+	err = configureServerCerts(endpoint, noCertCheck, c.GlobalIsSet("non-interactive"), fingerprints)
 	if err != nil {
 		return err
 	}
@@ -149,16 +248,130 @@ func showEndpoint(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	config = cf.ApplyOverrides(config)
 
 	if len(config.CloudTarget) == 0 {
 		fmt.Printf("No API target set\n")
 	} else {
 		fmt.Printf("Current API target is '%s'\n", config.CloudTarget)
 	}
+
+	contexts, err := cf.LoadContexts()
+	if err != nil {
+		return err
+	}
+	if len(contexts.Contexts) > 1 || len(cf.ContextOverride) != 0 {
+		fmt.Printf("Current context is '%s'\n", contexts.ActiveContextName())
+	}
 	return nil
 }
 
-// Store token in the config file
+// Make a context the current one
+func useContext(c *cli.Context) error {
+	err := checkArgNum(c.Args(), 1, "target use <name>")
+	if err != nil {
+		return err
+	}
+	name := c.Args().First()
+
+	err = cf.UseContext(name)
+	if err != nil {
+		return err
+	}
+
+	config, err := cf.LoadConfig()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Switched to context '%s' with API target '%s'\n", name, config.CloudTarget)
+	return nil
+}
+
+// Summary of a context, without its token
+type contextInfo struct {
+	Name        string `json:"name"`
+	Current     bool   `json:"current"`
+	CloudTarget string `json:"target"`
+	Tenant      string `json:"tenant,omitempty"`
+	Project     string `json:"project,omitempty"`
+	LoggedIn    bool   `json:"loggedIn"`
+}
+
+// Lists all the contexts in the config file
+func listContexts(c *cli.Context, w io.Writer) error {
+	err := checkArgNum(c.Args(), 0, "target list")
+	if err != nil {
+		return err
+	}
+
+	contexts, err := cf.LoadContexts()
+	if err != nil {
+		return err
+	}
+
+	activeName := contexts.ActiveContextName()
+	infoList := []contextInfo{}
+	for _, name := range contexts.Names() {
+		config := contexts.Contexts[name]
+		info := contextInfo{
+			Name:        name,
+			Current:     name == activeName,
+			CloudTarget: config.CloudTarget,
+			LoggedIn:    len(config.Token) != 0,
+		}
+		if config.Tenant != nil {
+			info.Tenant = config.Tenant.Name
+		}
+		if config.Project != nil {
+			info.Project = config.Project.Name
+		}
+		infoList = append(infoList, info)
+	}
+
+	if c.GlobalIsSet("non-interactive") {
+		for _, info := range infoList {
+			fmt.Fprintf(w, "%s\t%t\t%s\t%s\t%s\n", info.Name, info.Current, info.CloudTarget, info.Tenant, info.Project)
+		}
+	} else if utils.NeedsFormatting(c) {
+		utils.FormatObjects(infoList, w, c)
+	} else {
+		tw := new(tabwriter.Writer)
+		tw.Init(w, 4, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "Current\tName\tTarget\tTenant\tProject\n")
+		for _, info := range infoList {
+			current := ""
+			if info.Current {
+				current = "*"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", current, info.Name, info.CloudTarget, info.Tenant, info.Project)
+		}
+		err = tw.Flush()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\nTotal: %d\n", len(infoList))
+	}
+	return nil
+}
+
+// Deletes a context and the certificates it trusts
+func deleteContext(c *cli.Context) error {
+	err := checkArgNum(c.Args(), 1, "target delete <name>")
+	if err != nil {
+		return err
+	}
+	name := c.Args().First()
+
+	err = cf.DeleteContext(name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Context '%s' deleted\n", name)
+	return nil
+}
+
+// Store token in the credential store
 func login(c *cli.Context) error {
 	err := checkArgNum(c.Args(), 0, "target login")
 	if err != nil {
@@ -186,65 +399,90 @@ func login(c *cli.Context) error {
 	}
 
 	if len(token) == 0 && (len(username) == 0 || len(password) == 0) {
-		return fmt.Errorf("Please provide either a token or username/password")
-	}
-
-	config, err := cf.LoadConfig()
-	if err != nil {
-		return err
+		return usageError{"Please provide either a token or username/password"}
 	}
 
-	if len(token) > 0 {
-		config.Token = token
-
-	} else {
+	refreshToken := ""
+	if len(token) == 0 {
 		client.Esxclient, err = client.GetClient(c.GlobalIsSet("non-interactive"))
 		if err != nil {
 			return err
 		}
 
-		options, err := client.Esxclient.Auth.GetTokensByPassword(username, password)
+		options, err := client.GetTokensByPassword(client.Esxclient, username, password)
 		if err != nil {
 			return err
 		}
 
-		config.Token = options.AccessToken
+		token = options.AccessToken
+		refreshToken = options.RefreshToken
 	}
 
-	err = cf.SaveConfig(config)
+	err = cf.UpdateConfig(func(config *cf.Configuration) error {
+		config.Token = token
+		config.RefreshToken = refreshToken
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("Token stored in config file")
+	storeFile, err := getCredentialStoreFile()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Token stored in credential store %s\n", storeFile)
 
 	return nil
 }
 
-// Remove token from the config file
+// Remove token from the credential store
 func logout(c *cli.Context) error {
 	err := checkArgNum(c.Args(), 0, "target logout")
 	if err != nil {
 		return err
 	}
-	config, err := cf.LoadConfig()
+	err = cf.UpdateConfig(func(config *cf.Configuration) error {
+		config.Token = ""
+		config.RefreshToken = ""
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	config.Token = ""
-
-	err = cf.SaveConfig(config)
+	storeFile, err := getCredentialStoreFile()
 	if err != nil {
 		return err
 	}
-
-	fmt.Println("Token removed from config file")
+	fmt.Printf("Token removed from credential store %s\n", storeFile)
 
 	return nil
 }
 
-func configureServerCerts(endpoint string, noChertCheck bool, isNonInterractive bool) (err error) {
+// Returns the file of the credential store in use, for the messages of login and logout
+func getCredentialStoreFile() (string, error) {
+	contexts, err := cf.LoadContexts()
+	if err != nil {
+		return "", err
+	}
+	return cf.CredentialStoreFile(contexts.CredentialStore), nil
+}
+
+// Returns the absolute path of a file to save in the config file, so that it is found
+// from any directory
+func absolutePath(path string) string {
+	if len(path) == 0 {
+		return path
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return absPath
+}
+
+func configureServerCerts(endpoint string, noChertCheck bool, isNonInterractive bool, fingerprints []string) (err error) {
 	if noChertCheck {
 		return
 	}
@@ -261,7 +499,7 @@ func configureServerCerts(endpoint string, noChertCheck bool, isNonInterractive
 	// noCertCheck == false -> User wants server cert validation
 	// bTrusted = true -> Server cert is trusted
 	if u.Scheme == "https" {
-		err = setupApiServerCert(u.Host, isNonInterractive)
+		err = setupApiServerCert(u.Host, isNonInterractive, fingerprints)
 		if err != nil {
 			return
 		}
@@ -287,7 +525,7 @@ func configureServerCerts(endpoint string, noChertCheck bool, isNonInterractive
 	}
 
 	host := fmt.Sprintf("%s:%v", authInfo.Endpoint, authInfo.Port)
-	err = setupLightWaveCerts(host, isNonInterractive)
+	err = setupLightWaveCerts(host, isNonInterractive, fingerprints)
 	if err != nil {
 		return
 	}
@@ -295,9 +533,9 @@ func configureServerCerts(endpoint string, noChertCheck bool, isNonInterractive
 	return
 }
 
-func setupApiServerCert(host string, isNonInterractive bool) (err error) {
-	err = verifyServerTrust("API", host, isNonInterractive)
-	if err != nil {
+func setupApiServerCert(host string, isNonInterractive bool, fingerprints []string) (err error) {
+	bTrusted, err := verifyServerTrust("API", host, isNonInterractive, fingerprints)
+	if err != nil || bTrusted {
 		return
 	}
 
@@ -307,7 +545,7 @@ func setupApiServerCert(host string, isNonInterractive bool) (err error) {
 		return
 	}
 
-	err = processCert(cert, "API", host)
+	err = processCerts([]*x509.Certificate{cert}, "API", host, fingerprints)
 	if err != nil {
 		return
 	}
@@ -315,38 +553,43 @@ func setupApiServerCert(host string, isNonInterractive bool) (err error) {
 	return
 }
 
-func setupLightWaveCerts(host string, isNonInterractive bool) (err error) {
-	err = verifyServerTrust("Authentication", host, isNonInterractive)
-	if err != nil {
+func setupLightWaveCerts(host string, isNonInterractive bool, fingerprints []string) (err error) {
+	bTrusted, err := verifyServerTrust("Authentication", host, isNonInterractive, fingerprints)
+	if err != nil || bTrusted {
 		return
 	}
 
-	oidcClient := lightwave.NewOIDCClient(fmt.Sprintf("https://%s", host), nil, nil)
-	certs, err := oidcClient.GetRootCerts()
+	targetConfig, err := loadTargetConfig()
+	if err != nil {
+		return
+	}
+	certs, err := client.GetAuthServerCerts(targetConfig, host)
 	if err != nil {
 		return
 	}
 
-	for _, cert := range certs {
-		err = processCert(cert, "Authentication", host)
-		if err != nil {
-			return
-		}
+	err = processCerts(certs, "Authentication", host, fingerprints)
+	if err != nil {
+		return
 	}
 
 	return
 }
 
-func verifyServerTrust(serverName string, host string, isNonInterractive bool) (err error) {
+// Checks if the server is already trusted. If it isn't, its certificate can only be
+// trusted by prompting the user, or by comparing it with the given fingerprints.
+func verifyServerTrust(serverName string, host string, isNonInterractive bool, fingerprints []string) (bTrusted bool, err error) {
 	//check if we already trust the server
-	bTrusted, _ := isServerTrusted(host)
+	bTrusted, _ = isServerTrusted(host)
 	if bTrusted {
 		return
 	}
 
-	if isNonInterractive {
+	if isNonInterractive && len(fingerprints) == 0 {
 		err = fmt.Errorf(
-			"Could not establish trust with API server : %s.\nEither skip certificate validation or accept the server certificate in interactive mode\n",
+			"Could not establish trust with %s server : %s.\nEither skip certificate validation, give the certificate "+
+				"fingerprint with --trust-fingerprint or accept the server certificate in interactive mode\n",
+			serverName,
 			host)
 		return
 	}
@@ -354,15 +597,53 @@ func verifyServerTrust(serverName string, host string, isNonInterractive bool) (
 	return
 }
 
+// Trusts the certificates presented by a server: the ones matching the given
+// fingerprints, or else the ones the user accepts
+func processCerts(certs []*x509.Certificate, serverName string, host string, fingerprints []string) (err error) {
+	if len(fingerprints) == 0 {
+		for _, cert := range certs {
+			err = processCert(cert, serverName, host)
+			if err != nil {
+				return
+			}
+		}
+		return
+	}
+
+	bMatched := false
+	for _, cert := range certs {
+		if cert == nil || !matchesFingerprint(cert, fingerprints) {
+			continue
+		}
+		err = cf.AddCertToLocalStore(cert)
+		if err != nil {
+			return
+		}
+		bMatched = true
+		fmt.Printf("Trusted certificate %s presented by %s server %s\n", certFingerprint(cert), serverName, host)
+	}
+
+	if !bMatched {
+		presented := []string{}
+		for _, cert := range certs {
+			if cert != nil {
+				presented = append(presented, certFingerprint(cert))
+			}
+		}
+		err = fmt.Errorf("Certificate presented by %s server (%s) does not match --trust-fingerprint: got %s",
+			serverName, host, strings.Join(presented, ", "))
+	}
+	return
+}
+
 func processCert(cert *x509.Certificate, serverName string, host string) (err error) {
 	trustSrvCrt := ""
 	if cert != nil {
 		fmt.Printf(
-			"Certificate (with below fingerprint) presented by %s server (%s) isn't trusted.\nMD5 = %X\nSHA1  = %X\n",
+			"Certificate (with below fingerprint) presented by %s server (%s) isn't trusted.\nSHA-256 = %s\n",
 			serverName,
 			host,
-			md5.Sum(cert.Raw),
-			sha1.Sum(cert.Raw))
+			certFingerprint(cert))
 		//Get the user input on whether to trust the certificate
 		trustSrvCrt, err = askForInput("Do you trust this certificate for future communication? (yes/no): ", trustSrvCrt)
 	}
@@ -258,3 +258,83 @@ func TestLogout(t *testing.T) {
 		t.Error("Not expecting error when saving config file")
 	}
 }
+
+func TestTargetContexts(t *testing.T) {
+	contextsOri, err := cf.LoadContexts()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+
+	err = cf.RemoveConfigFile()
+	if err != nil {
+		t.Error("Not expecting error removing config file")
+	}
+
+	for _, name := range []string{"dev", "prod"} {
+		set := flag.NewFlagSet("test", 0)
+		set.Bool("nocertcheck", true, "")
+		set.String("name", "", "")
+		err = set.Parse([]string{"--nocertcheck", "--name", name, "http://" + name + ":9000"})
+		if err != nil {
+			t.Error("Not expecting arguments parsing to fail")
+		}
+		err = setEndpoint(cli.NewContext(nil, set, nil))
+		if err != nil {
+			t.Errorf("Not expecting error when setting endpoint for context %s: %s", name, err)
+		}
+	}
+
+	contexts, err := cf.LoadContexts()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+	if len(contexts.Contexts) != 2 || contexts.CurrentContext != "prod" {
+		t.Errorf("Expected contexts dev and prod with prod current, got %v", contexts.Names())
+	}
+
+	set := flag.NewFlagSet("test", 0)
+	err = set.Parse([]string{"dev"})
+	if err != nil {
+		t.Error("Not expecting arguments parsing to fail")
+	}
+	err = useContext(cli.NewContext(nil, set, nil))
+	if err != nil {
+		t.Error("Not expecting error when switching context")
+	}
+	config, err := cf.LoadConfig()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+	if config.CloudTarget != "http://dev:9000" {
+		t.Errorf("Expected target of context dev, got %s", config.CloudTarget)
+	}
+
+	cf.ContextOverride = "prod"
+	config, err = cf.LoadConfig()
+	cf.ContextOverride = ""
+	if err != nil || config.CloudTarget != "http://prod:9000" {
+		t.Error("Expected --context to override the current context")
+	}
+
+	set = flag.NewFlagSet("test", 0)
+	err = set.Parse([]string{"prod"})
+	if err != nil {
+		t.Error("Not expecting arguments parsing to fail")
+	}
+	err = deleteContext(cli.NewContext(nil, set, nil))
+	if err != nil {
+		t.Error("Not expecting error when deleting context")
+	}
+	contexts, err = cf.LoadContexts()
+	if err != nil {
+		t.Error("Not expecting error loading config file")
+	}
+	if len(contexts.Contexts) != 1 || contexts.CurrentContext != "dev" {
+		t.Errorf("Expected only context dev after delete, got %v", contexts.Names())
+	}
+
+	err = cf.SaveContexts(contextsOri)
+	if err != nil {
+		t.Error("Not expecting error when saving config file")
+	}
+}
@@ -11,17 +11,19 @@ package command
 
 import (
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/codegangsta/cli"
 	"github.com/vmware/photon-controller-go-sdk/photon"
 
 	"encoding/json"
 	"github.com/vmware/photon-controller-cli/photon/client"
+	"github.com/vmware/photon-controller-cli/photon/utils"
 )
 
 type stepSorter []photon.Step
@@ -30,10 +32,25 @@ func (step stepSorter) Len() int           { return len(step) }
 func (step stepSorter) Swap(i, j int)      { step[i], step[j] = step[j], step[i] }
 func (step stepSorter) Less(i, j int) bool { return step[i].Sequence < step[j].Sequence }
 
+type taskSorter []photon.Task
+
+func (task taskSorter) Len() int           { return len(task) }
+func (task taskSorter) Swap(i, j int)      { task[i], task[j] = task[j], task[i] }
+func (task taskSorter) Less(i, j int) bool { return task[i].StartedTime < task[j].StartedTime }
+
+// Filters of task list that the API does not support
+type taskListFilter struct {
+	since     time.Time
+	until     time.Time
+	operation string
+	limit     int
+}
+
 // Creates a cli.Command for tasks
 // Subcommands: list; Usage: task list [<options>]
 //              show; Usage: task show <id>
 //              monitor; Usage: task monitor <id>
+//              wait; Usage: task wait <id> [<id>...]
 func GetTasksCommand() cli.Command {
 	command := cli.Command{
 		Name:  "task",
@@ -55,21 +72,53 @@ func GetTasksCommand() cli.Command {
 						Name:  "state, s",
 						Usage: "specify task state for filtering",
 					},
+					cli.StringFlag{
+						Name:  "since",
+						Usage: "list tasks started since a time or for a duration, e.g. 2h or 2016-06-30T12:00:00Z",
+					},
+					cli.StringFlag{
+						Name:  "until",
+						Usage: "list tasks started before a time or a duration ago, e.g. 30m or 2016-06-30",
+					},
+					cli.StringFlag{
+						Name:  "operation",
+						Usage: "specify task operation for filtering, e.g. CREATE_VM",
+					},
+					cli.IntFlag{
+						Name:  "limit",
+						Usage: "list only the given number of most recently started tasks",
+					},
+					cli.IntFlag{
+						Name:  "page-size",
+						Usage: "number of tasks to get from the API at a time",
+					},
+					filterFlag("operation~=_VM$,entity.kind=vm"),
+					sortByFlag(".startedTime"),
+					cli.BoolFlag{
+						Name:  "watch, w",
+						Usage: "Poll for changes and print the rows that changed, until interrupted",
+					},
 				},
 				Action: func(c *cli.Context) {
 					err := listTasks(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
 			{
 				Name:  "show",
 				Usage: "Show task info with specified ID",
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "watch, w",
+						Usage: "Poll for changes and print the rows that changed, until interrupted",
+					},
+				},
 				Action: func(c *cli.Context) {
 					err := showTask(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -79,7 +128,23 @@ func GetTasksCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := monitorTask(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
+					}
+				},
+			},
+			{
+				Name:  "wait",
+				Usage: "Wait for the tasks with the specified IDs to end, fails if one of them did not complete",
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "fail-fast",
+						Usage: "Stop waiting for the other tasks as soon as one of them does not complete",
+					},
+				},
+				Action: func(c *cli.Context) {
+					err := waitTasks(c, os.Stdout)
+					if err != nil {
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -94,9 +159,20 @@ func listTasks(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	err = checkFilterAndSort(c)
+	if err != nil {
+		return err
+	}
 	entityId := c.String("entityId")
 	entityKind := c.String("entityKind")
 	state := c.String("state")
+	filter, err := getTaskListFilter(c, time.Now())
+	if err != nil {
+		return err
+	}
+	if c.Int("page-size") < 0 {
+		return usageError{"--page-size cannot be negative"}
+	}
 
 	client.Esxclient, err = client.GetClient(c.GlobalIsSet("non-interactive"))
 	if err != nil {
@@ -108,7 +184,29 @@ func listTasks(c *cli.Context) error {
 		EntityID:   entityId,
 		EntityKind: entityKind,
 	}
-	taskList, err := client.Esxclient.Tasks.GetAll(options)
+	pageSize := c.Int("page-size")
+	if c.Bool("watch") {
+		return watch(c, os.Stdout, func() (*watchSnapshot, error) {
+			taskList, err := client.GetTasks(client.Esxclient, options, pageSize)
+			if err != nil {
+				return nil, err
+			}
+			taskList.Items = filterTasks(taskList.Items, filter)
+			err = filterAndSortList(&taskList.Items, c)
+			if err != nil {
+				return nil, err
+			}
+			return taskListWatchSnapshot(taskList.Items), nil
+		})
+	}
+
+	taskList, err := client.GetTasks(client.Esxclient, options, pageSize)
+	if err != nil {
+		return err
+	}
+
+	taskList.Items = filterTasks(taskList.Items, filter)
+	err = filterAndSortList(&taskList.Items, c)
 	if err != nil {
 		return err
 	}
@@ -120,6 +218,68 @@ func listTasks(c *cli.Context) error {
 	return nil
 }
 
+// Returns the filters of task list given by its flags, with times relative to now
+func getTaskListFilter(c *cli.Context, now time.Time) (filter taskListFilter, err error) {
+	if len(c.String("since")) != 0 {
+		filter.since, err = parseTaskTime("since", c.String("since"), now)
+		if err != nil {
+			return
+		}
+	}
+	if len(c.String("until")) != 0 {
+		filter.until, err = parseTaskTime("until", c.String("until"), now)
+		if err != nil {
+			return
+		}
+	}
+	filter.operation = c.String("operation")
+	filter.limit = c.Int("limit")
+	if filter.limit < 0 {
+		err = usageError{"--limit cannot be negative"}
+	}
+	return
+}
+
+// Parses the time given to --since or --until: a duration before now, or a date
+// with or without a time
+func parseTaskTime(flag string, value string, now time.Time) (time.Time, error) {
+	duration, err := time.ParseDuration(value)
+	if err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		t, err := time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, usageError{fmt.Sprintf(
+		"Invalid --%s '%s', should be a duration such as 2h or a time such as 2016-06-30T12:00:00Z", flag, value)}
+}
+
+// Keeps the tasks matching the filter, sorted by the time they started
+func filterTasks(tasks []photon.Task, filter taskListFilter) []photon.Task {
+	kept := []photon.Task{}
+	for _, task := range tasks {
+		started := time.Unix(0, task.StartedTime*int64(time.Millisecond))
+		if !filter.since.IsZero() && started.Before(filter.since) {
+			continue
+		}
+		if !filter.until.IsZero() && !started.Before(filter.until) {
+			continue
+		}
+		if len(filter.operation) != 0 && !strings.EqualFold(task.Operation, filter.operation) {
+			continue
+		}
+		kept = append(kept, task)
+	}
+	sort.Stable(taskSorter(kept))
+	if filter.limit > 0 && len(kept) > filter.limit {
+		kept = kept[len(kept)-filter.limit:]
+	}
+	return kept
+}
+
 // Show the task current state, returns an error if one occurred
 func showTask(c *cli.Context) error {
 	err := checkArgNum(c.Args(), 1, "task show <task id>")
@@ -133,6 +293,16 @@ func showTask(c *cli.Context) error {
 		return err
 	}
 
+	if c.Bool("watch") {
+		return watch(c, os.Stdout, func() (*watchSnapshot, error) {
+			task, err := client.Esxclient.Tasks.Get(id)
+			if err != nil && task == nil {
+				return nil, err
+			}
+			return objectWatchSnapshot(task.ID, task)
+		})
+	}
+
 	task, taskError := client.Esxclient.Tasks.Get(id)
 	if taskError != nil && task == nil {
 		return taskError
@@ -187,7 +357,7 @@ func monitorTask(c *cli.Context) error {
 	}
 
 	if c.GlobalIsSet("non-interactive") {
-		task, err := client.Esxclient.Tasks.Wait(id)
+		task, err := waitTask(id)
 		if err != nil {
 			return err
 		}
@@ -210,6 +380,119 @@ func monitorTask(c *cli.Context) error {
 	return nil
 }
 
+// Error of task wait when some tasks did not complete. Its exit code is the one of the
+// first of these tasks.
+type taskWaitError struct {
+	failed int
+	total  int
+	first  error
+}
+
+func (e taskWaitError) Error() string {
+	return fmt.Sprintf("%d of %d tasks did not complete: %s", e.failed, e.total, e.first)
+}
+
+func (e taskWaitError) Unwrap() error {
+	return e.first
+}
+
+// Waits for tasks to end, returns an error if one of them did not complete
+func waitTasks(c *cli.Context, w io.Writer) error {
+	ids := c.Args()
+	if len(ids) == 0 {
+		return usageError{"Please provide argument. Usage: task wait <id> [<id>...]"}
+	}
+
+	var err error
+	client.Esxclient, err = client.GetClient(utils.IsNonInteractive(c))
+	if err != nil {
+		return err
+	}
+
+	policy := waitAll
+	if c.Bool("fail-fast") {
+		policy = failFast
+	}
+	var progress io.Writer
+	if !utils.IsNonInteractive(c) {
+		progress = w
+	}
+	options, err := getPollOptions(defaultTaskTimeout, defaultTaskPollInterval)
+	if err != nil {
+		return err
+	}
+	interrupted, stopWatching := watchInterrupt()
+	tasks, errs := waitForTasks(client.Esxclient, ids, options, policy, interrupted, progress)
+	stopWatching()
+
+	if c.GlobalIsSet("non-interactive") {
+		for i, id := range ids {
+			task := tasks[i]
+			if task == nil {
+				task = &photon.Task{ID: id}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", id, getTaskState(tasks[i], errs[i]), task.Entity.ID,
+				task.Entity.Kind)
+		}
+	} else if utils.NeedsFormatting(c) {
+		found := []photon.Task{}
+		for _, task := range tasks {
+			if task != nil {
+				found = append(found, *task)
+			}
+		}
+		utils.FormatObjects(found, w, c)
+	} else {
+		tw := new(tabwriter.Writer)
+		tw.Init(w, 4, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "Task\tOperation\tEntity\tState\tError\n")
+		for i, id := range ids {
+			task := tasks[i]
+			if task == nil {
+				task = &photon.Task{ID: id}
+			}
+			message := ""
+			if errs[i] != nil {
+				message = errs[i].Error()
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s %s\t%s\t%s\n", id, task.Operation, task.Entity.Kind, task.Entity.ID,
+				getTaskState(tasks[i], errs[i]), message)
+		}
+		err = tw.Flush()
+		if err != nil {
+			return err
+		}
+	}
+
+	select {
+	case <-interrupted:
+		running := []string{}
+		for i, id := range ids {
+			if errs[i] == errWaitCancelled {
+				running = append(running, id)
+			}
+		}
+		if len(running) != 0 {
+			return newTasksInterruptedError(running)
+		}
+	default:
+	}
+
+	waitErr := taskWaitError{total: len(ids)}
+	for _, err := range errs {
+		if err != nil {
+			if waitErr.first == nil || waitErr.first == errWaitCancelled {
+				waitErr.first = err
+			}
+			waitErr.failed++
+		}
+	}
+	if waitErr.failed != 0 {
+		return waitErr
+	}
+	return nil
+}
+
 func printTaskSteps(task *photon.Task, isScripting bool) error {
 	if isScripting {
 		for _, step := range task.Steps {
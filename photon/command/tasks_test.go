@@ -10,10 +10,12 @@
 package command
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/vmware/photon-controller-cli/photon/client"
 	"github.com/vmware/photon-controller-cli/photon/mocks"
@@ -96,6 +98,67 @@ func TestListTasks(t *testing.T) {
 	if err != nil {
 		t.Error("Not expecting error listing tasks with filter options: " + err.Error())
 	}
+
+	mocks.RegisterResponder(
+		"GET",
+		server.URL+"/tasks?state=ERROR&pageSize=50",
+		mocks.CreateResponder(200, string(response[:])))
+
+	set = flag.NewFlagSet("test", 0)
+	set.String("state", "ERROR", "state")
+	set.Int("page-size", 50, "page size")
+	cxt = cli.NewContext(nil, set, nil)
+
+	err = listTasks(cxt)
+	if err != nil {
+		t.Error("Not expecting error listing tasks with a page size: " + err.Error())
+	}
+}
+
+func TestFilterTasks(t *testing.T) {
+	now := time.Date(2016, 6, 30, 12, 0, 0, 0, time.UTC)
+	hoursAgo := func(hours int) int64 {
+		return now.Add(-time.Duration(hours)*time.Hour).UnixNano() / int64(time.Millisecond)
+	}
+	tasks := []photon.Task{
+		{ID: "task-1", Operation: "DELETE_VM", State: "ERROR", StartedTime: hoursAgo(3)},
+		{ID: "task-2", Operation: "CREATE_VM", State: "ERROR", StartedTime: hoursAgo(2)},
+		{ID: "task-3", Operation: "DELETE_VM", State: "ERROR", StartedTime: hoursAgo(10)},
+		{ID: "task-4", Operation: "DELETE_VM", State: "ERROR", StartedTime: hoursAgo(48)},
+		{ID: "task-5", Operation: "DELETE_VM", State: "ERROR", StartedTime: hoursAgo(0)},
+	}
+
+	set := flag.NewFlagSet("test", 0)
+	set.String("since", "24h", "since")
+	set.String("until", "1h", "until")
+	set.String("operation", "delete_vm", "operation")
+	set.Int("limit", 0, "limit")
+	filter, err := getTaskListFilter(cli.NewContext(nil, set, nil), now)
+	if err != nil {
+		t.Fatal("Not expecting error getting the task list filter: " + err.Error())
+	}
+	filtered := filterTasks(tasks, filter)
+	if len(filtered) != 2 || filtered[0].ID != "task-3" || filtered[1].ID != "task-1" {
+		t.Errorf("Expected the DELETE_VM tasks of the last day sorted by start time, got %v", filtered)
+	}
+
+	filter.limit = 1
+	filtered = filterTasks(tasks, filter)
+	if len(filtered) != 1 || filtered[0].ID != "task-1" {
+		t.Errorf("Expected only the most recent task, got %v", filtered)
+	}
+
+	set = flag.NewFlagSet("test", 0)
+	set.String("since", "yesterday", "since")
+	_, err = getTaskListFilter(cli.NewContext(nil, set, nil), now)
+	if classifyError(err).ExitCode != ExitUsage {
+		t.Errorf("Expected a usage error for an invalid time, got %v", err)
+	}
+
+	since, err := parseTaskTime("since", "2016-06-30T10:00:00Z", now)
+	if err != nil || !since.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("Expected the time to be parsed, got %s and %v", since, err)
+	}
 }
 
 func TestShowMonitorTask(t *testing.T) {
@@ -137,3 +200,106 @@ func TestShowMonitorTask(t *testing.T) {
 		t.Error("Not expecting error monitoring task: " + err.Error())
 	}
 }
+
+func TestWaitTasks(t *testing.T) {
+	completedTask := photon.Task{
+		Operation: "CREATE_VM",
+		State:     "COMPLETED",
+		ID:        "fake-completed-task-id",
+		Entity:    photon.Entity{ID: "fake-vm-id", Kind: "vm"},
+	}
+	failedTask := photon.Task{
+		Operation: "DELETE_VM",
+		State:     "ERROR",
+		ID:        "fake-failed-task-id",
+		Entity:    photon.Entity{ID: "fake-vm-id", Kind: "vm"},
+	}
+	server := mocks.NewTestServer()
+	for _, task := range []photon.Task{completedTask, failedTask} {
+		response, err := json.Marshal(task)
+		if err != nil {
+			t.Error("Not expecting error serializing expected task")
+		}
+		mocks.RegisterResponder(
+			"GET",
+			server.URL+"/tasks/"+task.ID,
+			mocks.CreateResponder(200, string(response[:])))
+	}
+	defer server.Close()
+
+	mocks.Activate(true)
+	httpClient := &http.Client{Transport: mocks.DefaultMockTransport}
+	client.Esxclient = photon.NewTestClient(server.URL, nil, httpClient)
+
+	var buf bytes.Buffer
+	err := waitTasks(newConfigContext(t, []string{"-non-interactive"}, []string{"fake-completed-task-id"}), &buf)
+	if err != nil {
+		t.Error("Not expecting error waiting for a completed task: " + err.Error())
+	}
+	if buf.String() != "fake-completed-task-id\tCOMPLETED\tfake-vm-id\tvm\n" {
+		t.Errorf("Unexpected output: %s", buf.String())
+	}
+
+	buf.Reset()
+	err = waitTasks(newConfigContext(t, []string{"-output", "json"},
+		[]string{"fake-completed-task-id", "fake-failed-task-id"}), &buf)
+	if err == nil || classifyError(err).ExitCode != ExitTaskError {
+		t.Errorf("Expected a task error when a task failed, got %v", err)
+	}
+	var tasks []photon.Task
+	err = json.Unmarshal(buf.Bytes(), &tasks)
+	if err != nil || len(tasks) != 2 || tasks[1].State != "ERROR" {
+		t.Errorf("Expected both tasks in the output, got %s", buf.String())
+	}
+}
+
+func TestAsyncTaskOperation(t *testing.T) {
+	task := photon.Task{
+		Operation: "CREATE_VM",
+		State:     "QUEUED",
+		ID:        "fake-queued-task-id",
+		Entity:    photon.Entity{ID: "fake-vm-id", Kind: "vm"},
+	}
+	response, err := json.Marshal(task)
+	if err != nil {
+		t.Error("Not expecting error serializing expected task")
+	}
+	server := mocks.NewTestServer()
+	mocks.RegisterResponder(
+		"GET",
+		server.URL+"/tasks/"+task.ID,
+		mocks.CreateResponder(200, string(response[:])))
+	defer server.Close()
+
+	mocks.Activate(true)
+	httpClient := &http.Client{Transport: mocks.DefaultMockTransport}
+	client.Esxclient = photon.NewTestClient(server.URL, nil, httpClient)
+
+	globalSet := flag.NewFlagSet("test", 0)
+	globalSet.Bool("non-interactive", true, "doc")
+	globalSet.Bool("async", true, "doc")
+	err = globalSet.Parse([]string{"-non-interactive", "-async"})
+	if err != nil {
+		t.Error("Not expecting arguments parsing to fail")
+	}
+	cxt := cli.NewContext(nil, flag.NewFlagSet("test", 0), cli.NewContext(nil, globalSet, nil))
+
+	id, err := waitOnTaskOperation(&task, cxt)
+	if err != errTaskStarted || id != "" {
+		t.Errorf("Expected the task to be started without waiting, got %v", err)
+	}
+
+	mocks.RegisterResponder(
+		"GET",
+		server.URL+"/tasks/fake-unreadable-task-id",
+		mocks.CreateResponder(500, "{}"))
+	_, err = waitOnTaskOperation(&photon.Task{ID: "fake-unreadable-task-id"}, cxt)
+	if err != errTaskStarted {
+		t.Errorf("Expected the task to be started even if it cannot be read, got %v", err)
+	}
+
+	err = destroy(cxt)
+	if classifyError(err).ExitCode != ExitUsage {
+		t.Errorf("Expected system destroy to reject --async, got %v", err)
+	}
+}
@@ -12,7 +12,6 @@ package command
 import (
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"regexp"
 	"strings"
@@ -51,7 +50,7 @@ func GetTenantsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createTenant(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -61,7 +60,7 @@ func GetTenantsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := deleteTenant(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -71,7 +70,7 @@ func GetTenantsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := showTenant(c, os.Stdout)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -81,7 +80,7 @@ func GetTenantsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listTenants(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -91,7 +90,7 @@ func GetTenantsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := setTenant(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -101,7 +100,7 @@ func GetTenantsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getTenant(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -117,7 +116,7 @@ func GetTenantsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getTenantTasks(c, os.Stdout)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -127,7 +126,7 @@ func GetTenantsCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := setSecurityGroups(c)
 					if err != nil {
-						log.Fatal("Error: ", err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -140,7 +139,7 @@ func GetTenantsCommand() cli.Command {
 // Returns an error if one occurred
 func createTenant(c *cli.Context, w io.Writer) error {
 	if len(c.Args()) > 1 {
-		return fmt.Errorf("Unknown argument: %v", c.Args()[1:])
+		return usageError{fmt.Sprintf("Unknown argument: %v", c.Args()[1:])}
 	}
 	name := c.Args().First()
 	securityGroups := c.String("security-groups")
@@ -160,7 +159,7 @@ func createTenant(c *cli.Context, w io.Writer) error {
 	}
 
 	if len(name) == 0 {
-		return fmt.Errorf("Please provide tenant name")
+		return usageError{"Please provide tenant name"}
 	}
 	securityGroupList := []string{}
 	if securityGroups != "" {
@@ -184,7 +183,7 @@ func createTenant(c *cli.Context, w io.Writer) error {
 		return err
 	}
 
-	id, err := waitOnTaskOperation(createTask.ID, c)
+	id, err := waitOnTaskOperation(createTask, c)
 	if err != nil {
 		return err
 	}
@@ -258,7 +257,7 @@ func deleteTenant(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(deleteTask.ID, c)
+	_, err = waitOnTaskOperation(deleteTask, c)
 	if err != nil {
 		return err
 	}
@@ -329,18 +328,11 @@ func setTenant(c *cli.Context) error {
 		return err
 	}
 
-	config, err := cf.LoadConfig()
-	if err != nil {
-		return err
-	}
-
-	config.Tenant = &cf.TenantConfiguration{Name: name, ID: id}
-	err = cf.SaveConfig(config)
-	if err != nil {
-		return err
-	}
-
-	err = clearConfigProject("")
+	err = cf.UpdateConfig(func(config *cf.Configuration) error {
+		config.Tenant = &cf.TenantConfiguration{Name: name, ID: id}
+		config.Project = nil
+		return nil
+	})
 	if err != nil {
 		return err
 	}
@@ -432,7 +424,7 @@ func setSecurityGroups(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
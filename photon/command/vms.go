@@ -12,7 +12,6 @@ package command
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -96,7 +95,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createVM(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -106,17 +105,23 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := deleteVM(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
 			{
 				Name:  "show",
 				Usage: "Show VM info with specified ID",
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "watch, w",
+						Usage: "Poll for changes and print the rows that changed, until interrupted",
+					},
+				},
 				Action: func(c *cli.Context) {
 					err := showVM(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -140,11 +145,17 @@ func GetVMCommand() cli.Command {
 						Name:  "name, n",
 						Usage: "VM name",
 					},
+					filterFlag("state=STARTED,flavor~=large"),
+					sortByFlag(".name"),
+					cli.BoolFlag{
+						Name:  "watch, w",
+						Usage: "Poll for changes and print the rows that changed, until interrupted",
+					},
 				},
 				Action: func(c *cli.Context) {
 					err := listVMs(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -160,7 +171,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getVMTasks(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -170,7 +181,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := startVM(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -180,7 +191,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := stopVM(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -190,7 +201,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := suspendVM(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -200,7 +211,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := resumeVM(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -210,7 +221,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := restartVM(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -227,7 +238,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := attachDisk(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -244,7 +255,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := detachDisk(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -264,7 +275,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := attachIso(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -274,7 +285,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := detachIso(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -290,7 +301,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := setVMMetadata(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -306,7 +317,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := setVMTag(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -316,7 +327,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := listVMNetworks(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -326,7 +337,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := getVMMksTicket(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -346,7 +357,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := createVmImage(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -362,7 +373,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := aquireFloatingIp(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -378,7 +389,7 @@ func GetVMCommand() cli.Command {
 				Action: func(c *cli.Context) {
 					err := releaseFloatingIp(c)
 					if err != nil {
-						log.Fatal(err)
+						exitWithError(c, err)
 					}
 				},
 			},
@@ -445,7 +456,7 @@ func createVM(c *cli.Context) error {
 	}
 
 	if len(name) == 0 || len(flavor) == 0 || len(imageID) == 0 {
-		return fmt.Errorf("Please provide name, flavor and image")
+		return usageError{"Please provide name, flavor and image"}
 	}
 
 	var environmentMap map[string]string
@@ -493,7 +504,7 @@ func createVM(c *cli.Context) error {
 		if err != nil {
 			return err
 		}
-		_, err = waitOnTaskOperation(createTask.ID, c)
+		_, err = waitOnTaskOperation(createTask, c)
 		if err != nil {
 			return err
 		}
@@ -523,7 +534,7 @@ func deleteVM(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(deleteTask.ID, c)
+	_, err = waitOnTaskOperation(deleteTask, c)
 	if err != nil {
 		return err
 	}
@@ -545,6 +556,16 @@ func showVM(c *cli.Context) error {
 		return err
 	}
 
+	if c.Bool("watch") {
+		return watch(c, os.Stdout, func() (*watchSnapshot, error) {
+			vm, err := client.Esxclient.VMs.Get(id)
+			if err != nil {
+				return nil, err
+			}
+			return objectWatchSnapshot(vm.ID, vm)
+		})
+	}
+
 	vm, err := client.Esxclient.VMs.Get(id)
 	if err != nil {
 		return err
@@ -633,6 +654,10 @@ func listVMs(c *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	err = checkFilterAndSort(c)
+	if err != nil {
+		return err
+	}
 
 	tenantName := c.String("tenant")
 	projectName := c.String("project")
@@ -657,11 +682,30 @@ func listVMs(c *cli.Context) error {
 		return err
 	}
 
+	if c.Bool("watch") {
+		return watch(c, os.Stdout, func() (*watchSnapshot, error) {
+			vmList, err := client.Esxclient.Projects.GetVMs(project.ID, options)
+			if err != nil {
+				return nil, err
+			}
+			err = filterAndSortList(&vmList.Items, c)
+			if err != nil {
+				return nil, err
+			}
+			return vmListWatchSnapshot(vmList.Items), nil
+		})
+	}
+
 	vmList, err := client.Esxclient.Projects.GetVMs(project.ID, options)
 	if err != nil {
 		return err
 	}
 
+	err = filterAndSortList(&vmList.Items, c)
+	if err != nil {
+		return err
+	}
+
 	err = printVMList(vmList.Items, os.Stdout, c, summaryView)
 	if err != nil {
 		return err
@@ -720,7 +764,7 @@ func startVM(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(opTask.ID, c)
+	_, err = waitOnTaskOperation(opTask, c)
 	if err != nil {
 		return err
 	}
@@ -746,7 +790,7 @@ func stopVM(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(opTask.ID, c)
+	_, err = waitOnTaskOperation(opTask, c)
 	if err != nil {
 		return err
 	}
@@ -772,7 +816,7 @@ func suspendVM(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(opTask.ID, c)
+	_, err = waitOnTaskOperation(opTask, c)
 	if err != nil {
 		return err
 	}
@@ -798,7 +842,7 @@ func resumeVM(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(opTask.ID, c)
+	_, err = waitOnTaskOperation(opTask, c)
 	if err != nil {
 		return err
 	}
@@ -824,7 +868,7 @@ func restartVM(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(opTask.ID, c)
+	_, err = waitOnTaskOperation(opTask, c)
 	if err != nil {
 		return err
 	}
@@ -855,7 +899,7 @@ func attachDisk(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
@@ -886,7 +930,7 @@ func detachDisk(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
@@ -916,7 +960,7 @@ func attachIso(c *cli.Context) error {
 	}
 
 	if len(path) == 0 {
-		return fmt.Errorf("Please provide iso path")
+		return usageError{"Please provide iso path"}
 	}
 	if len(name) == 0 {
 		name = filepath.Base(path)
@@ -942,7 +986,7 @@ func attachIso(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
@@ -968,7 +1012,7 @@ func detachIso(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
@@ -993,7 +1037,7 @@ func setVMMetadata(c *cli.Context) error {
 	vmMetadata := &photon.VmMetadata{}
 
 	if len(metadata) == 0 {
-		return fmt.Errorf("Please provide metadata")
+		return usageError{"Please provide metadata"}
 	} else {
 		var data map[string]string
 		err := json.Unmarshal([]byte(metadata), &data)
@@ -1008,7 +1052,7 @@ func setVMMetadata(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
@@ -1052,7 +1096,7 @@ func setVMTag(c *cli.Context) error {
 	vmTag := &photon.VmTag{}
 
 	if len(tag) == 0 {
-		return fmt.Errorf("Please input a tag")
+		return usageError{"Please input a tag"}
 	}
 	vmTag.Tag = tag
 
@@ -1066,7 +1110,7 @@ func setVMTag(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
@@ -1093,7 +1137,7 @@ func getVMMksTicket(c *cli.Context) error {
 	}
 
 	if c.GlobalIsSet("non-interactive") {
-		task, err := client.Esxclient.Tasks.Wait(task.ID)
+		task, err := waitTask(task.ID)
 		if err != nil {
 			return err
 		}
@@ -1157,7 +1201,7 @@ func createVmImage(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
@@ -1195,7 +1239,7 @@ func aquireFloatingIp(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
@@ -1233,7 +1277,7 @@ func releaseFloatingIp(c *cli.Context) error {
 		return err
 	}
 
-	_, err = waitOnTaskOperation(task.ID, c)
+	_, err = waitOnTaskOperation(task, c)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,304 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vmware/photon-controller-cli/photon/utils"
+
+	"github.com/codegangsta/cli"
+	"github.com/vmware/photon-controller-go-sdk/photon"
+)
+
+// Time between two polls of the API in watch mode
+var watchInterval = 2 * time.Second
+
+// Markers for the rows printed in watch mode
+const (
+	watchAdded   = "+"
+	watchRemoved = "-"
+	watchChanged = "~"
+)
+
+// One row printed in watch mode. For lists, there is one row per object; for show
+// commands, there is one row per field of the object.
+type watchRow struct {
+	key      string
+	objectID string
+	cells    []string
+	object   interface{}
+}
+
+// What a watch command sees on each poll of the API
+type watchSnapshot struct {
+	header []string
+	rows   []watchRow
+}
+
+// Event printed for each changed object with any --output format
+type watchEvent struct {
+	Type   string      `json:"type"`
+	Object interface{} `json:"object"`
+}
+
+// Polls the API until the command is interrupted. The first poll prints everything,
+// later polls only print the rows that were added, removed or changed.
+func watch(c *cli.Context, w io.Writer, poll func() (*watchSnapshot, error)) error {
+	printer := &watchPrinter{w: w, c: c}
+	var previous *watchSnapshot
+	for {
+		current, err := poll()
+		if err != nil {
+			if previous == nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		} else {
+			err = printer.printChanges(previous, current)
+			if err != nil {
+				return err
+			}
+			previous = current
+		}
+		time.Sleep(watchInterval)
+	}
+}
+
+// A row with the marker telling how it changed
+type watchChange struct {
+	marker string
+	row    watchRow
+}
+
+// Compares two snapshots and returns the rows that changed, in the order of the current
+// snapshot followed by the removed rows. Formatted output compares the whole objects,
+// since they contain more than the columns of the table.
+func diffWatchSnapshots(previous *watchSnapshot, current *watchSnapshot, compareObjects bool) []watchChange {
+	changes := []watchChange{}
+	if previous == nil {
+		for _, row := range current.rows {
+			changes = append(changes, watchChange{watchAdded, row})
+		}
+		return changes
+	}
+
+	previousRows := map[string]watchRow{}
+	for _, row := range previous.rows {
+		previousRows[row.key] = row
+	}
+	currentKeys := map[string]bool{}
+	for _, row := range current.rows {
+		currentKeys[row.key] = true
+		previousRow, ok := previousRows[row.key]
+		if !ok {
+			changes = append(changes, watchChange{watchAdded, row})
+		} else if compareObjects && !sameJson(previousRow.object, row.object) {
+			changes = append(changes, watchChange{watchChanged, row})
+		} else if !compareObjects && !reflect.DeepEqual(previousRow.cells, row.cells) {
+			changes = append(changes, watchChange{watchChanged, row})
+		}
+	}
+	for _, row := range previous.rows {
+		if !currentKeys[row.key] {
+			changes = append(changes, watchChange{watchRemoved, row})
+		}
+	}
+	return changes
+}
+
+// Tells if two objects have the same JSON representation
+func sameJson(a interface{}, b interface{}) bool {
+	aJson, aErr := json.Marshal(a)
+	bJson, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aJson) == string(bJson)
+}
+
+// Prints the changes of a whole watch. The table keeps the same column widths from one
+// poll to the next, so that the rows printed on each poll line up with the first ones;
+// a column only gets wider when a row does not fit in it.
+type watchPrinter struct {
+	w      io.Writer
+	c      *cli.Context
+	widths []int
+}
+
+// Prints what changed between two polls
+func (p *watchPrinter) printChanges(previous *watchSnapshot, current *watchSnapshot) error {
+	if utils.NeedsFormatting(p.c) {
+		return printWatchEvents(p.w, p.c, previous, current)
+	}
+
+	changes := diffWatchSnapshots(previous, current, false)
+	if p.c.GlobalIsSet("non-interactive") {
+		for _, change := range changes {
+			fmt.Fprintf(p.w, "%s\t%s\n", change.marker, strings.Join(change.row.cells, "\t"))
+		}
+		return nil
+	}
+
+	if previous == nil {
+		// Size the columns for all the rows of the first poll, not only the changed ones
+		p.fit(current.header)
+		for _, row := range current.rows {
+			p.fit(row.cells)
+		}
+		p.printRow(" ", current.header)
+	}
+	for _, change := range changes {
+		marker := change.marker
+		if previous == nil {
+			marker = " "
+		}
+		p.fit(change.row.cells)
+		p.printRow(marker, change.row.cells)
+	}
+	return nil
+}
+
+// Widens the columns that are too narrow for the given cells
+func (p *watchPrinter) fit(cells []string) {
+	for i, cell := range cells {
+		if i == len(p.widths) {
+			p.widths = append(p.widths, 0)
+		}
+		width := utf8.RuneCountInString(cell)
+		if width > p.widths[i] {
+			p.widths[i] = width
+		}
+	}
+}
+
+// Prints a row of the table, padding the cells like the tables of the other commands
+func (p *watchPrinter) printRow(marker string, cells []string) {
+	line := marker + " "
+	for i, cell := range cells {
+		line += cell
+		if i < len(cells)-1 {
+			line += strings.Repeat(" ", watchColumnWidth(p.widths[i])-utf8.RuneCountInString(cell))
+		}
+	}
+	fmt.Fprintln(p.w, line)
+}
+
+// Width of a column holding cells of the given width, with the padding and minimum
+// width of the tabwriter used for tables
+func watchColumnWidth(width int) int {
+	if width+2 < 4 {
+		return 4
+	}
+	return width + 2
+}
+
+// Prints one event per changed object, with every output format: an object with the
+// type of change (ADDED, MODIFIED or DELETED) and the object itself, so that e.g.
+// custom columns and JSONPath templates refer to its fields as .object.<field>
+func printWatchEvents(w io.Writer, c *cli.Context, previous *watchSnapshot, current *watchSnapshot) error {
+	previousObjects := map[string]bool{}
+	if previous != nil {
+		for _, row := range previous.rows {
+			previousObjects[row.objectID] = true
+		}
+	}
+
+	printed := map[string]bool{}
+	for _, change := range diffWatchSnapshots(previous, current, true) {
+		if printed[change.row.objectID] {
+			continue
+		}
+		printed[change.row.objectID] = true
+
+		event := watchEvent{Type: "MODIFIED", Object: change.row.object}
+		if change.marker == watchRemoved {
+			event.Type = "DELETED"
+		} else if !previousObjects[change.row.objectID] {
+			event.Type = "ADDED"
+		}
+		utils.FormatObject(event, w, c)
+	}
+	return nil
+}
+
+// Snapshot of a single object for show commands, with one row per field
+func objectWatchSnapshot(id string, o interface{}) (*watchSnapshot, error) {
+	fields, values, err := utils.FlattenObject(o)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &watchSnapshot{header: []string{"Field", "Value"}}
+	for i, field := range fields {
+		snapshot.rows = append(snapshot.rows, watchRow{
+			key:      field,
+			objectID: id,
+			cells:    []string{field, values[i]},
+			object:   o,
+		})
+	}
+	return snapshot, nil
+}
+
+func vmListWatchSnapshot(vmList []photon.VM) *watchSnapshot {
+	snapshot := &watchSnapshot{header: []string{"ID", "Name", "State"}}
+	for _, vm := range vmList {
+		snapshot.rows = append(snapshot.rows, watchRow{
+			key:      vm.ID,
+			objectID: vm.ID,
+			cells:    []string{vm.ID, vm.Name, vm.State},
+			object:   vm,
+		})
+	}
+	return snapshot
+}
+
+func hostListWatchSnapshot(hostList []photon.Host) *watchSnapshot {
+	snapshot := &watchSnapshot{header: []string{"ID", "State", "IP", "Tags"}}
+	for _, host := range hostList {
+		snapshot.rows = append(snapshot.rows, watchRow{
+			key:      host.ID,
+			objectID: host.ID,
+			cells:    []string{host.ID, host.State, host.Address, strings.Join(host.Tags, " ")},
+			object:   host,
+		})
+	}
+	return snapshot
+}
+
+func clusterListWatchSnapshot(clusterList []photon.Cluster) *watchSnapshot {
+	snapshot := &watchSnapshot{header: []string{"ID", "Name", "Type", "State", "Worker Count"}}
+	for _, cluster := range clusterList {
+		snapshot.rows = append(snapshot.rows, watchRow{
+			key:      cluster.ID,
+			objectID: cluster.ID,
+			cells:    []string{cluster.ID, cluster.Name, cluster.Type, cluster.State, fmt.Sprint(cluster.WorkerCount)},
+			object:   cluster,
+		})
+	}
+	return snapshot
+}
+
+func taskListWatchSnapshot(taskList []photon.Task) *watchSnapshot {
+	snapshot := &watchSnapshot{header: []string{"Task", "Operation", "State", "Start Time"}}
+	for _, task := range taskList {
+		snapshot.rows = append(snapshot.rows, watchRow{
+			key:      task.ID,
+			objectID: task.ID,
+			cells:    []string{task.ID, task.Operation, task.State, timestampToString(task.StartedTime)},
+			object:   task,
+		})
+	}
+	return snapshot
+}
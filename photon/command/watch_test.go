@@ -0,0 +1,170 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/vmware/photon-controller-go-sdk/photon"
+)
+
+func newWatchContext(t *testing.T, args []string) *cli.Context {
+	globalSet := flag.NewFlagSet("test", 0)
+	globalSet.Bool("non-interactive", false, "doc")
+	globalSet.String("output", "", "doc")
+	err := globalSet.Parse(args)
+	if err != nil {
+		t.Error("Not expecting arguments parsing to fail")
+	}
+	globalCtx := cli.NewContext(nil, globalSet, nil)
+	return cli.NewContext(nil, flag.NewFlagSet("test", 0), globalCtx)
+}
+
+func TestPrintWatchChanges(t *testing.T) {
+	first := hostListWatchSnapshot([]photon.Host{
+		{ID: "host-1", State: "READY", Address: "10.0.0.1"},
+		{ID: "host-2", State: "READY", Address: "10.0.0.2"},
+	})
+	second := hostListWatchSnapshot([]photon.Host{
+		{ID: "host-1", State: "MAINTENANCE", Address: "10.0.0.1"},
+		{ID: "host-3", State: "CREATING", Address: "10.0.0.3"},
+	})
+	third := hostListWatchSnapshot([]photon.Host{
+		{ID: "host-1", State: "MAINTENANCE", Address: "10.0.0.1"},
+		{ID: "host-3", State: "CREATING", Address: "10.0.0.3"},
+	})
+
+	var output bytes.Buffer
+	printer := &watchPrinter{w: &output, c: newWatchContext(t, []string{"--non-interactive"})}
+	err := printer.printChanges(nil, first)
+	if err != nil {
+		t.Error(err)
+	}
+	err = printer.printChanges(first, second)
+	if err != nil {
+		t.Error(err)
+	}
+	err = printer.printChanges(second, third)
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := "+\thost-1\tREADY\t10.0.0.1\t\n" +
+		"+\thost-2\tREADY\t10.0.0.2\t\n" +
+		"~\thost-1\tMAINTENANCE\t10.0.0.1\t\n" +
+		"+\thost-3\tCREATING\t10.0.0.3\t\n" +
+		"-\thost-2\tREADY\t10.0.0.2\t\n"
+	if output.String() != expected {
+		t.Errorf("Unexpected watch output, expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+
+	output.Reset()
+	printer = &watchPrinter{w: &output, c: newWatchContext(t, []string{})}
+	err = printer.printChanges(nil, first)
+	if err != nil {
+		t.Error(err)
+	}
+	lines := strings.Split(output.String(), "\n")
+	if !strings.HasPrefix(lines[0], "  ID") || !strings.HasPrefix(lines[1], "  host-1") {
+		t.Errorf("Unexpected watch table:\n%s", output.String())
+	}
+}
+
+// The rows printed on later polls line up with the table of the first poll
+func TestPrintWatchChangesColumnWidths(t *testing.T) {
+	first := hostListWatchSnapshot([]photon.Host{
+		{ID: "host-1", State: "MAINTENANCE", Address: "10.0.0.1"},
+		{ID: "host-2", State: "READY", Address: "10.0.0.2"},
+	})
+	second := hostListWatchSnapshot([]photon.Host{
+		{ID: "host-1", State: "MAINTENANCE", Address: "10.0.0.1"},
+		{ID: "host-2", State: "ERROR", Address: "10.0.0.2"},
+	})
+
+	var output bytes.Buffer
+	printer := &watchPrinter{w: &output, c: newWatchContext(t, []string{})}
+	err := printer.printChanges(nil, first)
+	if err != nil {
+		t.Error(err)
+	}
+	err = printer.printChanges(first, second)
+	if err != nil {
+		t.Error(err)
+	}
+
+	lines := strings.Split(output.String(), "\n")
+	if len(lines) != 5 || lines[3] != "~ host-2  ERROR        10.0.0.2  " {
+		t.Errorf("Unexpected watch table:\n%s", output.String())
+	}
+	if strings.Index(lines[0], "IP") != strings.Index(lines[3], "10.0.0.2") {
+		t.Errorf("Expected the changed row to line up with the header:\n%s", output.String())
+	}
+}
+
+// Every output format prints the same event, with the object under "object"
+func TestPrintWatchEventsFormats(t *testing.T) {
+	first := hostListWatchSnapshot([]photon.Host{{ID: "host-1", State: "READY"}})
+	second := hostListWatchSnapshot([]photon.Host{{ID: "host-1", State: "MAINTENANCE"}})
+
+	cases := []struct {
+		output   string
+		expected string
+	}{
+		{"--output=jsonpath={.type} {.object.id} {.object.state}", "MODIFIED host-1 MAINTENANCE"},
+		{"--output=custom-columns=TYPE:.type,ID:.object.id", "MODIFIED  host-1"},
+		{"--output=csv", "type,object.username"},
+	}
+	for _, testCase := range cases {
+		var output bytes.Buffer
+		printer := &watchPrinter{w: &output, c: newWatchContext(t, []string{testCase.output})}
+		err := printer.printChanges(first, second)
+		if err != nil {
+			t.Error(err)
+		}
+		if !strings.Contains(output.String(), testCase.expected) {
+			t.Errorf("Expected '%s' with %s, got:\n%s", testCase.expected, testCase.output, output.String())
+		}
+	}
+}
+
+func TestPrintWatchEvents(t *testing.T) {
+	first, err := objectWatchSnapshot("vm-1", photon.VM{ID: "vm-1", Name: "fake-vm", State: "STOPPED"})
+	if err != nil {
+		t.Error(err)
+	}
+	second, err := objectWatchSnapshot("vm-1", photon.VM{ID: "vm-1", Name: "fake-vm", State: "STARTED"})
+	if err != nil {
+		t.Error(err)
+	}
+
+	var output bytes.Buffer
+	printer := &watchPrinter{w: &output, c: newWatchContext(t, []string{"--output=json"})}
+	err = printer.printChanges(first, second)
+	if err != nil {
+		t.Error(err)
+	}
+
+	var event struct {
+		Type   string
+		Object photon.VM
+	}
+	err = json.Unmarshal(output.Bytes(), &event)
+	if err != nil {
+		t.Errorf("Could not parse watch event '%s': %s", output.String(), err)
+	}
+	if event.Type != "MODIFIED" || event.Object.State != "STARTED" {
+		t.Errorf("Unexpected watch event: %s", output.String())
+	}
+}
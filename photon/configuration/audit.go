@@ -0,0 +1,116 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+)
+
+const auditLogFileName = "audit.log"
+
+// The audit log is rotated when it grows over this size, keeping this many old logs
+// as audit.log.1 (the most recent) to audit.log.<n>
+var (
+	MaxAuditLogSize int64 = 10 * 1024 * 1024
+	AuditLogBackups       = 5
+)
+
+// One line of the audit log, recorded for each operation that changes something
+type AuditRecord struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Host       string    `json:"host,omitempty"`
+	User       string    `json:"user,omitempty"`
+	Target     string    `json:"target"`
+	Command    []string  `json:"command"`
+	TaskID     string    `json:"taskId"`
+	Operation  string    `json:"operation,omitempty"`
+	EntityKind string    `json:"entityKind,omitempty"`
+	EntityID   string    `json:"entityId,omitempty"`
+	State      string    `json:"state"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Returns the path of the audit log
+func GetAuditLogPath() (string, error) {
+	userConfigDir, err := getUserConfigDirectory()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(userConfigDir, auditLogFileName), nil
+}
+
+// Appends a record to the audit log as a line of JSON, after rotating the log if it is
+// full. Both hold the config file lock, so that photon commands running at the same time
+// neither rotate the log twice nor append to a log that is being renamed.
+func WriteAuditRecord(record *AuditRecord) error {
+	logPath, err := GetAuditLogPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return withConfigLock(func() error {
+		err := rotateAuditLog(logPath)
+		if err != nil {
+			return err
+		}
+		return appendAuditLog(logPath, data)
+	})
+}
+
+func appendAuditLog(logPath string, data []byte) (err error) {
+	file, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer checkClose(&err, file)
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// Renames audit.log to audit.log.1, audit.log.1 to audit.log.2 and so on when it is
+// over MaxAuditLogSize, removing the oldest log
+func rotateAuditLog(logPath string) error {
+	info, err := os.Stat(logPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() < MaxAuditLogSize {
+		return nil
+	}
+
+	backupPath := func(i int) string {
+		return fmt.Sprintf("%s.%d", logPath, i)
+	}
+	err = os.Remove(backupPath(AuditLogBackups))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := AuditLogBackups - 1; i >= 1; i-- {
+		err = os.Rename(backupPath(i), backupPath(i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if AuditLogBackups == 0 {
+		return os.Remove(logPath)
+	}
+	return os.Rename(logPath, backupPath(1))
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/vmware/photon-controller-cli/photon/configuration"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var _ = Describe("Audit", func() {
+	var (
+		maxSize int64
+		backups int
+	)
+
+	BeforeEach(func() {
+		var err error
+		UserConfigDir, err = ioutil.TempDir("", "audit-test-")
+		Expect(err).To(BeNil())
+		maxSize = MaxAuditLogSize
+		backups = AuditLogBackups
+	})
+
+	AfterEach(func() {
+		MaxAuditLogSize = maxSize
+		AuditLogBackups = backups
+		err := os.RemoveAll(UserConfigDir)
+		Expect(err).To(BeNil())
+	})
+
+	It("appends records as JSON lines", func() {
+		err := WriteAuditRecord(&AuditRecord{TaskID: "task-1", State: "COMPLETED"})
+		Expect(err).To(BeNil())
+		err = WriteAuditRecord(&AuditRecord{TaskID: "task-2", State: "ERROR"})
+		Expect(err).To(BeNil())
+
+		logPath, err := GetAuditLogPath()
+		Expect(err).To(BeNil())
+		info, err := os.Stat(logPath)
+		Expect(err).To(BeNil())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		data, err := ioutil.ReadFile(logPath)
+		Expect(err).To(BeNil())
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		Expect(lines).To(HaveLen(2))
+		record := AuditRecord{}
+		err = json.Unmarshal([]byte(lines[1]), &record)
+		Expect(err).To(BeNil())
+		Expect(record.TaskID).To(Equal("task-2"))
+		Expect(record.State).To(Equal("ERROR"))
+	})
+
+	It("rotates the log when it is full", func() {
+		MaxAuditLogSize = 1
+		AuditLogBackups = 2
+		for _, id := range []string{"task-1", "task-2", "task-3", "task-4"} {
+			err := WriteAuditRecord(&AuditRecord{TaskID: id})
+			Expect(err).To(BeNil())
+		}
+
+		logPath, err := GetAuditLogPath()
+		Expect(err).To(BeNil())
+		for suffix, id := range map[string]string{"": "task-4", ".1": "task-3", ".2": "task-2"} {
+			data, err := ioutil.ReadFile(logPath + suffix)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(ContainSubstring(id))
+		}
+		_, err = os.Stat(logPath + ".3")
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("keeps every record when commands rotate the log at the same time", func() {
+		MaxAuditLogSize = 1
+		AuditLogBackups = 20
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- WriteAuditRecord(&AuditRecord{TaskID: fmt.Sprintf("task-%d", i)})
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			Expect(err).To(BeNil())
+		}
+
+		// Each record fills the log, so each one ends up alone in its own file
+		logPath, err := GetAuditLogPath()
+		Expect(err).To(BeNil())
+		paths, err := filepath.Glob(logPath + "*")
+		Expect(err).To(BeNil())
+		Expect(paths).To(HaveLen(10))
+		for _, p := range paths {
+			data, err := ioutil.ReadFile(p)
+			Expect(err).To(BeNil())
+			Expect(strings.Split(strings.TrimSpace(string(data)), "\n")).To(HaveLen(1))
+		}
+	})
+})
@@ -12,7 +12,6 @@ package configuration
 import (
 	"crypto/sha1"
 	"crypto/x509"
-	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -35,48 +34,100 @@ type ProjectConfiguration struct {
 type Configuration struct {
 	CloudTarget       string
 	Token             string
+	RefreshToken      string `json:",omitempty"`
 	IgnoreCertificate bool
 	Tenant            *TenantConfiguration
 	Project           *ProjectConfiguration
+	// Proxy for the target, instead of the one given by HTTPS_PROXY and NO_PROXY
+	Proxy string `json:",omitempty"`
+	// PEM file with certificate authorities trusted in addition to the system ones
+	CAFile string `json:",omitempty"`
+	// PEM files with the certificate and private key presented to the target; the key
+	// can be in the certificate file
+	ClientCertFile string `json:",omitempty"`
+	ClientKeyFile  string `json:",omitempty"`
+	// How long to wait for tasks, and how often to poll them at first, e.g. 2h and 1s,
+	// unless given with --timeout and --poll-interval
+	TaskTimeout      string `json:",omitempty"`
+	TaskPollInterval string `json:",omitempty"`
+
+	// Error reading the tokens from the credential store, see CredentialsError
+	credentialsErr error
 }
 
-// Load configuration in config file
+// Returns the error that prevented reading the tokens of the context from the
+// credential store, if any. Only the commands that need the tokens fail with it.
+func (config *Configuration) CredentialsError() error {
+	return config.credentialsErr
+}
+
+// Load the configuration of the context in use from config file
 func LoadConfig() (*Configuration, error) {
-	filepath, err := getConfigurationFilePath()
+	contexts, err := LoadContexts()
 	if err != nil {
 		return &Configuration{}, err
 	}
 
-	if isFileExist(filepath) {
-		config, err := readConfigFromFile(filepath)
-		if err != nil {
-			return &Configuration{}, err
+	name := contexts.ActiveContextName()
+	config, ok := contexts.Contexts[name]
+	if !ok {
+		if len(ContextOverride) != 0 {
+			return &Configuration{}, fmt.Errorf("Context '%s' does not exist", name)
 		}
-		return config, nil
+		return &Configuration{}, nil
 	}
 
-	return &Configuration{}, nil
+	return config, nil
 }
 
-// Save configuration into config file, will overwrite config file
+// Save configuration of the context in use into config file, leaving the other
+// contexts unchanged. A config file that cannot be read is not overwritten.
 func SaveConfig(config *Configuration) error {
-	filepath, err := getConfigurationFilePath()
-	if err != nil {
-		return err
-	}
+	return UpdateContexts(func(contexts *ContextsConfiguration) error {
+		setActiveConfig(contexts, config)
+		return nil
+	})
+}
 
-	err = writeConfigToFile(filepath, config)
-	if err != nil {
-		return err
-	}
+// Update the configuration of the context in use: while no other photon command can
+// write the config file, load the configuration, let update change it and save it.
+// Unlike LoadConfig followed by SaveConfig, this does not undo changes saved by
+// another photon command in the meantime.
+func UpdateConfig(update func(config *Configuration) error) error {
+	return UpdateContexts(func(contexts *ContextsConfiguration) error {
+		name := contexts.ActiveContextName()
+		config, ok := contexts.Contexts[name]
+		if !ok {
+			if len(ContextOverride) != 0 {
+				return fmt.Errorf("Context '%s' does not exist", name)
+			}
+			config = &Configuration{}
+		}
+		err := update(config)
+		if err != nil {
+			return err
+		}
+		setActiveConfig(contexts, config)
+		return nil
+	})
+}
 
-	return nil
+func setActiveConfig(contexts *ContextsConfiguration, config *Configuration) {
+	name := contexts.ActiveContextName()
+	contexts.Contexts[name] = config
+	if len(contexts.CurrentContext) == 0 {
+		contexts.CurrentContext = name
+	}
 }
 
 var UserConfigDir string
 
 func getUserConfigDirectory() (userConfigDir string, err error) {
 	if len(UserConfigDir) != 0 {
+		//Ensure Config Dir Exists, e.g. when given with PHOTON_CONFIG_DIR
+		if !isFileExist(UserConfigDir) {
+			err = os.MkdirAll(UserConfigDir, 0755)
+		}
 		return UserConfigDir, err
 	}
 
@@ -136,42 +187,57 @@ func isFileExist(path string) bool {
 	}
 }
 
-// Read and deserialize configuration form local config file in JSON format
-func readConfigFromFile(path string) (*Configuration, error) {
+// Read the local config file
+func readConfigFile(path string) ([]byte, error) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("Error loading configuration: %v", err)
 	}
+	return data, nil
+}
 
-	var config Configuration
-	err = json.Unmarshal(data, &config)
+// Write serialized configuration to local config file, readable only by the user.
+// The data is written to a temporary file that then replaces the config file, so
+// that a photon command reading it never sees a partly written file.
+func writeConfigToFile(path string, data []byte) (err error) {
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
 	if err != nil {
-		return nil, fmt.Errorf("Error loading configuration: %v", err)
+		return fmt.Errorf("Error saving configuration: %v", err)
 	}
+	defer func() {
+		if err != nil {
+			os.Remove(file.Name())
+		}
+	}()
 
-	return &config, nil
-}
-
-// Serialize and write configuration to local config file in JSON format
-func writeConfigToFile(path string, config *Configuration) error {
-	data, err := json.Marshal(*config)
+	err = writeAndClose(file, data)
 	if err != nil {
 		return fmt.Errorf("Error saving configuration: %v", err)
 	}
 
-	file, err := os.Create(path)
+	err = os.Rename(file.Name(), path)
 	if err != nil {
 		return fmt.Errorf("Error saving configuration: %v", err)
 	}
 
+	return nil
+}
+
+func writeAndClose(file *os.File, data []byte) (err error) {
 	defer checkClose(&err, file)
 
+	// Files created by older versions were readable by everyone
+	err = file.Chmod(0600)
+	if err != nil {
+		return err
+	}
+
 	_, err = file.Write(data)
 	if err != nil {
-		return fmt.Errorf("Error saving configuration: %v", err)
+		return err
 	}
 
-	return nil
+	return file.Sync()
 }
 
 func checkClose(errp *error, c io.Closer) {
@@ -181,19 +247,30 @@ func checkClose(errp *error, c io.Closer) {
 	}
 }
 
-func getCertsDir() (string, error) {
-	var err error
-	err = nil
+func getCertsRootDir() (string, error) {
 	//Get Cert directory by adding .pc-certs to the HOME diretory of user
 	certsDir, err := getUserConfigDirectory()
 	if err != nil {
 		return certsDir, err
 	}
 	certsDir = path.Join(certsDir, ".photon-cli-certs")
+	return certsDir, err
+}
+
+// Get the directory with the certificates trusted by the context in use
+func getCertsDir() (string, error) {
+	contexts, err := LoadContexts()
+	if err != nil {
+		return "", err
+	}
+	certsDir, err := getContextCertsDir(contexts.ActiveContextName())
+	if err != nil {
+		return certsDir, err
+	}
 
 	//Ensure Certs Dir Exists - if not create it
 	if !isFileExist(certsDir) {
-		err := os.Mkdir(certsDir, 0755)
+		err := os.MkdirAll(certsDir, 0755)
 		if err != nil {
 			fmt.Println(err)
 		}
@@ -219,11 +296,22 @@ func generateFileNameFromCert(cert *x509.Certificate) (string, error) {
 func GetCertsFromLocalStore() (*x509.CertPool, error) {
 	roots := x509.NewCertPool()
 
+	certs, err := ListCertsInLocalStore()
+	for _, cert := range certs {
+		roots.AddCert(cert)
+	}
+	return roots, err
+}
+
+// Get the certificates trusted by the context in use, sorted by file name
+func ListCertsInLocalStore() ([]*x509.Certificate, error) {
+	certs := []*x509.Certificate{}
+
 	//If we can't get the certs dir itself it's bad
 	certsDir, err := getCertsDir()
 	if err != nil {
 		fmt.Println(err)
-		return roots, err
+		return certs, err
 	}
 	files, err := ioutil.ReadDir(certsDir)
 
@@ -238,7 +326,7 @@ func GetCertsFromLocalStore() (*x509.CertPool, error) {
 		if err == nil {
 			ca, err := x509.ParseCertificate(ca_b)
 			if err == nil {
-				roots.AddCert(ca)
+				certs = append(certs, ca)
 			} else {
 				fmt.Println(err)
 			}
@@ -246,8 +334,9 @@ func GetCertsFromLocalStore() (*x509.CertPool, error) {
 			fmt.Println(err)
 		}
 	}
-	return roots, err
+	return certs, err
 }
+
 func AddCertToLocalStore(cert *x509.Certificate) error {
 	ca_f, err := generateFileNameFromCert(cert)
 
@@ -15,6 +15,7 @@ import (
 	. "github.com/vmware/photon-controller-cli/photon/configuration"
 	"io/ioutil"
 	"os"
+	"path"
 )
 
 var _ = Describe("Config", func() {
@@ -56,8 +57,9 @@ var _ = Describe("Config", func() {
 			It("returns empty config and error", func() {
 				config, err := LoadConfig()
 
-				Expect(err).To(
-					MatchError("Error loading configuration: invalid character '<' looking for beginning of value"))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(".photon-config is incomplete or corrupt"))
+				Expect(err.Error()).To(ContainSubstring("invalid character '<' looking for beginning of value"))
 				Expect(config).To(BeEquivalentTo(&Configuration{}))
 			})
 		})
@@ -107,7 +109,7 @@ var _ = Describe("Config", func() {
 
 		Context("when config file exists", func() {
 			BeforeEach(func() {
-				config := "{CloudTarget: \"http://localhost:9080\"}"
+				config := "{\"CloudTarget\": \"http://localhost:9080\"}"
 
 				err := ChangeConfigFileContents(config)
 				Expect(err).To(BeNil())
@@ -126,5 +128,24 @@ var _ = Describe("Config", func() {
 				Expect(config).To(BeEquivalentTo(configExpected))
 			})
 		})
+
+		Context("when config file is corrupted", func() {
+			BeforeEach(func() {
+				config := "{CloudTarget: \"http://localhost:9080\"}"
+
+				err := ChangeConfigFileContents(config)
+				Expect(err).To(BeNil())
+			})
+
+			It("does not overwrite it", func() {
+				err := SaveConfig(&Configuration{CloudTarget: "test-write-to-file-3"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(".photon-config is incomplete or corrupt"))
+
+				data, err := ioutil.ReadFile(path.Join(UserConfigDir, ".photon-config"))
+				Expect(err).To(BeNil())
+				Expect(string(data)).To(Equal("{CloudTarget: \"http://localhost:9080\"}"))
+			})
+		})
 	})
 })
@@ -0,0 +1,264 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+)
+
+// Name of the context used when none was named, e.g. for configurations written
+// before contexts existed
+const DefaultContextName = "default"
+
+// Name of the context to use instead of the current one, set with the global -context flag
+var ContextOverride string
+
+var contextNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Version of the config file format written by this CLI. Files without a version
+// were written before it was introduced.
+const ConfigVersion = 1
+
+// Contents of the config file: the named contexts, each one with its own target,
+// token, tenant and project, and the name of the context in use
+type ContextsConfiguration struct {
+	Version         int
+	CurrentContext  string
+	CredentialStore string `json:",omitempty"`
+	Contexts        map[string]*Configuration
+}
+
+// Load all the contexts in the config file. It is never written here: tokens that
+// are not in the credential store in use are moved there when the contexts are next
+// saved. When the credential store cannot be read, e.g. without its passphrase, the
+// tokens are left empty and the error is only returned when they are needed, see
+// Configuration.CredentialsError.
+func LoadContexts() (*ContextsConfiguration, error) {
+	contexts, err := readContexts()
+	if err != nil {
+		return &ContextsConfiguration{}, err
+	}
+	err = contexts.loadCredentials()
+	if err != nil {
+		for _, config := range contexts.Contexts {
+			if len(config.Token) == 0 && len(config.RefreshToken) == 0 {
+				config.credentialsErr = err
+			}
+		}
+	}
+	return contexts, nil
+}
+
+// Save all the contexts into the config file, will overwrite config file
+func SaveContexts(contexts *ContextsConfiguration) error {
+	return withConfigLock(func() error {
+		return saveContexts(contexts)
+	})
+}
+
+// Update the contexts in the config file: while no other photon command can write
+// it, load the contexts, let update change them and save them. The config file is
+// not saved if update returns an error.
+func UpdateContexts(update func(contexts *ContextsConfiguration) error) error {
+	return withConfigLock(func() error {
+		contexts, err := loadContexts()
+		if err != nil {
+			return err
+		}
+		if contexts.Contexts == nil {
+			contexts.Contexts = map[string]*Configuration{}
+		}
+		err = update(contexts)
+		if err != nil {
+			return err
+		}
+		return saveContexts(contexts)
+	})
+}
+
+// Load the contexts and their credentials, failing if the credentials cannot be read
+// so that they are not lost when the contexts are saved again
+func loadContexts() (*ContextsConfiguration, error) {
+	contexts, err := readContexts()
+	if err != nil {
+		return &ContextsConfiguration{}, err
+	}
+	err = contexts.loadCredentials()
+	if err != nil {
+		return &ContextsConfiguration{}, err
+	}
+	return contexts, nil
+}
+
+// Read the contexts from the config file, without their credentials
+func readContexts() (*ContextsConfiguration, error) {
+	filepath, err := getConfigurationFilePath()
+	if err != nil {
+		return &ContextsConfiguration{}, err
+	}
+	if !isFileExist(filepath) {
+		return &ContextsConfiguration{}, nil
+	}
+	return readContextsFromFile(filepath)
+}
+
+// Save the contexts, without taking the config file lock
+func saveContexts(contexts *ContextsConfiguration) error {
+	filepath, err := getConfigurationFilePath()
+	if err != nil {
+		return err
+	}
+
+	withoutTokens, err := contexts.saveCredentials()
+	if err != nil {
+		return err
+	}
+	withoutTokens.Version = ConfigVersion
+
+	data, err := json.Marshal(*withoutTokens)
+	if err != nil {
+		return fmt.Errorf("Error saving configuration: %v", err)
+	}
+
+	return writeConfigToFile(filepath, data)
+}
+
+// Returns the name of the context in use: the one given with -context, else the current one
+func (contexts *ContextsConfiguration) ActiveContextName() string {
+	if len(ContextOverride) != 0 {
+		return ContextOverride
+	}
+	if len(contexts.CurrentContext) != 0 {
+		return contexts.CurrentContext
+	}
+	return DefaultContextName
+}
+
+// Returns the names of all contexts, sorted
+func (contexts *ContextsConfiguration) Names() []string {
+	names := []string{}
+	for name := range contexts.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Make the named context the current one
+func UseContext(name string) error {
+	return UpdateContexts(func(contexts *ContextsConfiguration) error {
+		if _, ok := contexts.Contexts[name]; !ok {
+			return fmt.Errorf("Context '%s' does not exist", name)
+		}
+		contexts.CurrentContext = name
+		return nil
+	})
+}
+
+// Remove the named context, along with the certificates it trusts
+func DeleteContext(name string) error {
+	err := UpdateContexts(func(contexts *ContextsConfiguration) error {
+		if _, ok := contexts.Contexts[name]; !ok {
+			return fmt.Errorf("Context '%s' does not exist", name)
+		}
+		delete(contexts.Contexts, name)
+		if contexts.CurrentContext == name {
+			contexts.CurrentContext = ""
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if name != DefaultContextName {
+		certsDir, err := getContextCertsDir(name)
+		if err != nil {
+			return err
+		}
+		return os.RemoveAll(certsDir)
+	}
+	return nil
+}
+
+// Check that a context name can be used, in particular as a directory name
+func ValidateContextName(name string) error {
+	if !contextNameRegexp.MatchString(name) {
+		return fmt.Errorf("Invalid context name '%s': use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// Read and deserialize the contexts from the local config file in JSON format.
+// A config file written before contexts existed holds a single configuration,
+// which becomes the default context.
+func readContextsFromFile(path string) (*ContextsConfiguration, error) {
+	data, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// A file left empty by an interrupted write of an older CLI holds no configuration
+	if len(bytes.TrimSpace(data)) == 0 {
+		return &ContextsConfiguration{Contexts: map[string]*Configuration{}}, nil
+	}
+
+	var contexts ContextsConfiguration
+	err = json.Unmarshal(data, &contexts)
+	if err != nil {
+		return nil, corruptConfigError(path, err)
+	}
+	if contexts.Version > ConfigVersion {
+		return nil, fmt.Errorf("Error loading configuration: %s was written by a newer photon CLI "+
+			"(format version %d, this CLI reads up to %d)", path, contexts.Version, ConfigVersion)
+	}
+
+	if len(contexts.Contexts) == 0 {
+		var config Configuration
+		err = json.Unmarshal(data, &config)
+		if err != nil {
+			return nil, corruptConfigError(path, err)
+		}
+		contexts.Contexts = map[string]*Configuration{}
+		if config != (Configuration{}) {
+			contexts.Contexts[DefaultContextName] = &config
+			contexts.CurrentContext = DefaultContextName
+		}
+	}
+
+	return &contexts, nil
+}
+
+// Error for a config file that cannot be parsed, e.g. because an older CLI was
+// interrupted while writing it
+func corruptConfigError(path string, err error) error {
+	return fmt.Errorf("Error loading configuration: %s is incomplete or corrupt (%v), "+
+		"fix it, or remove it and set the target again", path, err)
+}
+
+// Certificates trusted by a context. The default context uses the top-level
+// directory, so that certificates trusted before contexts existed are kept.
+func getContextCertsDir(name string) (string, error) {
+	certsDir, err := getCertsRootDir()
+	if err != nil || name == DefaultContextName {
+		return certsDir, err
+	}
+	err = ValidateContextName(name)
+	if err != nil {
+		return certsDir, err
+	}
+	return path.Join(certsDir, "contexts", name), nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/vmware/photon-controller-cli/photon/configuration"
+	"io/ioutil"
+	"os"
+)
+
+var _ = Describe("Contexts", func() {
+	BeforeEach(func() {
+		var err error
+		UserConfigDir, err = ioutil.TempDir("", "contexts-test-")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		ContextOverride = ""
+		err := os.RemoveAll(UserConfigDir)
+		Expect(err).To(BeNil())
+	})
+
+	Describe("LoadContexts", func() {
+		Context("when config file has a single target", func() {
+			BeforeEach(func() {
+				err := ChangeConfigFileContents("{\"CloudTarget\": \"http://localhost:9080\", \"Token\": \"token\"}")
+				Expect(err).To(BeNil())
+			})
+
+			It("returns it as the default context", func() {
+				contexts, err := LoadContexts()
+
+				Expect(err).To(BeNil())
+				Expect(contexts.CurrentContext).To(Equal(DefaultContextName))
+				Expect(contexts.Names()).To(Equal([]string{DefaultContextName}))
+				Expect(contexts.Contexts[DefaultContextName].CloudTarget).To(Equal("http://localhost:9080"))
+				Expect(contexts.Contexts[DefaultContextName].Token).To(Equal("token"))
+			})
+		})
+	})
+
+	Describe("SaveConfig", func() {
+		Context("when there are several contexts", func() {
+			BeforeEach(func() {
+				err := SaveContexts(&ContextsConfiguration{
+					CurrentContext: "dev",
+					Contexts: map[string]*Configuration{
+						"dev":  {CloudTarget: "http://dev:9000"},
+						"prod": {CloudTarget: "http://prod:9000"},
+					},
+				})
+				Expect(err).To(BeNil())
+			})
+
+			It("only changes the current context", func() {
+				err := SaveConfig(&Configuration{CloudTarget: "http://dev:9001"})
+				Expect(err).To(BeNil())
+
+				contexts, err := LoadContexts()
+				Expect(err).To(BeNil())
+				Expect(contexts.Contexts["dev"].CloudTarget).To(Equal("http://dev:9001"))
+				Expect(contexts.Contexts["prod"].CloudTarget).To(Equal("http://prod:9000"))
+			})
+
+			It("changes the context given with ContextOverride", func() {
+				ContextOverride = "prod"
+				err := SaveConfig(&Configuration{CloudTarget: "http://prod:9001"})
+				Expect(err).To(BeNil())
+
+				ContextOverride = ""
+				contexts, err := LoadContexts()
+				Expect(err).To(BeNil())
+				Expect(contexts.CurrentContext).To(Equal("dev"))
+				Expect(contexts.Contexts["prod"].CloudTarget).To(Equal("http://prod:9001"))
+			})
+
+			It("fails to load a context that does not exist", func() {
+				ContextOverride = "staging"
+				_, err := LoadConfig()
+				Expect(err).To(MatchError("Context 'staging' does not exist"))
+			})
+		})
+	})
+
+	Describe("UseContext", func() {
+		It("fails when the context does not exist", func() {
+			err := UseContext("staging")
+			Expect(err).To(MatchError("Context 'staging' does not exist"))
+		})
+	})
+})
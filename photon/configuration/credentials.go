@@ -0,0 +1,320 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Names of the credential stores, set with CredentialStore in the config file
+const (
+	// Tokens are encrypted in .photon-credentials; this is the default
+	EncryptedFileStoreName = "encrypted-file"
+	// Tokens are kept in .photon-config, as before credential stores existed
+	PlaintextStoreName = "plaintext"
+)
+
+// Environment variable with the passphrase used to encrypt the credentials.
+// Without it, a random key saved in .photon-cli-key is used. That key is kept next to
+// the credentials, so it only keeps the tokens out of the config file, and does not
+// protect them from anyone who can read the user's files.
+const PassphraseEnvVar = "PHOTON_CLI_PASSPHRASE"
+
+// Secrets of one context, kept out of the config file by the credential store
+type Credentials struct {
+	Token        string
+	RefreshToken string `json:",omitempty"`
+}
+
+// Stores the credentials of all the contexts
+type CredentialStore interface {
+	Load() (map[string]Credentials, error)
+	Save(credentials map[string]Credentials) error
+}
+
+// Returns the credential store with the given name; an empty name is the default store
+func GetCredentialStore(name string) (CredentialStore, error) {
+	switch name {
+	case "", EncryptedFileStoreName:
+		return &encryptedFileStore{}, nil
+	case PlaintextStoreName:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("Unknown credential store '%s', should be %s or %s",
+			name, EncryptedFileStoreName, PlaintextStoreName)
+	}
+}
+
+// Returns the file in which the credential store with the given name keeps the tokens
+func CredentialStoreFile(name string) string {
+	if name == PlaintextStoreName {
+		return ".photon-config"
+	}
+	return ".photon-credentials"
+}
+
+// Fills in the tokens of the contexts from the credential store. Tokens that are not
+// where the store in use keeps them are moved there when the contexts are saved: tokens
+// written to the config file by an older CLI, or tokens left in the encrypted file
+// after switching to the plaintext store.
+func (contexts *ContextsConfiguration) loadCredentials() error {
+	store, err := GetCredentialStore(contexts.CredentialStore)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		filepath, err := getCredentialsFilePath()
+		if err != nil || !isFileExist(filepath) {
+			return err
+		}
+		store = &encryptedFileStore{}
+	}
+
+	credentials, err := store.Load()
+	if err != nil {
+		return err
+	}
+	for name, config := range contexts.Contexts {
+		if len(config.Token) != 0 || len(config.RefreshToken) != 0 {
+			continue
+		}
+		config.Token = credentials[name].Token
+		config.RefreshToken = credentials[name].RefreshToken
+	}
+	return nil
+}
+
+// Saves the tokens of the contexts in the credential store, and returns the contexts
+// to write to the config file, without tokens unless the store is plaintext
+func (contexts *ContextsConfiguration) saveCredentials() (*ContextsConfiguration, error) {
+	store, err := GetCredentialStore(contexts.CredentialStore)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		// Remove the encrypted credentials, which are now in the config file
+		return contexts, (&encryptedFileStore{}).Save(nil)
+	}
+
+	credentials := map[string]Credentials{}
+	withoutTokens := *contexts
+	withoutTokens.Contexts = map[string]*Configuration{}
+	for name, config := range contexts.Contexts {
+		if len(config.Token) != 0 || len(config.RefreshToken) != 0 {
+			credentials[name] = Credentials{Token: config.Token, RefreshToken: config.RefreshToken}
+		}
+		configWithoutTokens := *config
+		configWithoutTokens.Token = ""
+		configWithoutTokens.RefreshToken = ""
+		withoutTokens.Contexts[name] = &configWithoutTokens
+	}
+
+	err = store.Save(credentials)
+	if err != nil {
+		return nil, err
+	}
+	return &withoutTokens, nil
+}
+
+// Contents of .photon-credentials
+type encryptedCredentials struct {
+	// "passphrase" or "machine-key"
+	KeySource string
+	Salt      []byte `json:",omitempty"`
+	Nonce     []byte
+	Data      []byte
+}
+
+const (
+	passphraseKeySource = "passphrase"
+	machineKeySource    = "machine-key"
+	pbkdf2Iterations    = 100000
+	keyLength           = 32
+)
+
+// Credential store encrypting the credentials with AES-256-GCM, with a key derived from
+// a passphrase or a random key readable only by the user
+type encryptedFileStore struct{}
+
+func getCredentialsFilePath() (string, error) {
+	userConfigDir, err := getUserConfigDirectory()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(userConfigDir, ".photon-credentials"), nil
+}
+
+func (store *encryptedFileStore) Load() (map[string]Credentials, error) {
+	credentials := map[string]Credentials{}
+	filepath, err := getCredentialsFilePath()
+	if err != nil {
+		return credentials, err
+	}
+	if !isFileExist(filepath) {
+		return credentials, nil
+	}
+
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return credentials, fmt.Errorf("Error loading credentials: %v", err)
+	}
+	var encrypted encryptedCredentials
+	err = json.Unmarshal(data, &encrypted)
+	if err != nil {
+		return credentials, fmt.Errorf("Error loading credentials: %v", err)
+	}
+
+	key, err := getCredentialsKey(encrypted.KeySource, encrypted.Salt, false)
+	if err != nil {
+		return credentials, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return credentials, err
+	}
+	plaintext, err := gcm.Open(nil, encrypted.Nonce, encrypted.Data, nil)
+	if err != nil {
+		if encrypted.KeySource == passphraseKeySource {
+			return credentials, fmt.Errorf("Cannot decrypt credentials, check %s", PassphraseEnvVar)
+		}
+		return credentials, fmt.Errorf("Cannot decrypt credentials: %v", err)
+	}
+
+	err = json.Unmarshal(plaintext, &credentials)
+	if err != nil {
+		return credentials, fmt.Errorf("Error loading credentials: %v", err)
+	}
+	return credentials, nil
+}
+
+func (store *encryptedFileStore) Save(credentials map[string]Credentials) error {
+	filepath, err := getCredentialsFilePath()
+	if err != nil {
+		return err
+	}
+	if len(credentials) == 0 {
+		if isFileExist(filepath) {
+			return os.Remove(filepath)
+		}
+		return nil
+	}
+
+	plaintext, err := json.Marshal(credentials)
+	if err != nil {
+		return fmt.Errorf("Error saving credentials: %v", err)
+	}
+
+	encrypted := encryptedCredentials{KeySource: machineKeySource}
+	if len(os.Getenv(PassphraseEnvVar)) != 0 {
+		encrypted.KeySource = passphraseKeySource
+		encrypted.Salt, err = randomBytes(16)
+		if err != nil {
+			return err
+		}
+	}
+	key, err := getCredentialsKey(encrypted.KeySource, encrypted.Salt, true)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	encrypted.Nonce, err = randomBytes(gcm.NonceSize())
+	if err != nil {
+		return err
+	}
+	encrypted.Data = gcm.Seal(nil, encrypted.Nonce, plaintext, nil)
+
+	data, err := json.Marshal(encrypted)
+	if err != nil {
+		return fmt.Errorf("Error saving credentials: %v", err)
+	}
+	return writeConfigToFile(filepath, data)
+}
+
+// Returns the key for the credentials: derived from the passphrase, or else the
+// machine key, which is created if needed
+func getCredentialsKey(keySource string, salt []byte, create bool) ([]byte, error) {
+	switch keySource {
+	case passphraseKeySource:
+		passphrase := os.Getenv(PassphraseEnvVar)
+		if len(passphrase) == 0 {
+			return nil, fmt.Errorf("Credentials are encrypted with a passphrase, set %s", PassphraseEnvVar)
+		}
+		return pbkdf2.Key([]byte(passphrase), salt, pbkdf2Iterations, keyLength, sha256.New), nil
+	case machineKeySource:
+		return getMachineKey(create)
+	default:
+		return nil, fmt.Errorf("Error loading credentials: unknown key source '%s'", keySource)
+	}
+}
+
+// Reads the random key in .photon-cli-key, creating it if needed. Anyone who can read
+// the credentials can read this key too.
+func getMachineKey(create bool) ([]byte, error) {
+	userConfigDir, err := getUserConfigDirectory()
+	if err != nil {
+		return nil, err
+	}
+	keyPath := path.Join(userConfigDir, ".photon-cli-key")
+
+	if isFileExist(keyPath) {
+		key, err := ioutil.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading credentials key: %v", err)
+		}
+		if len(key) != keyLength {
+			return nil, fmt.Errorf("Error reading credentials key: invalid key in %s", keyPath)
+		}
+		return key, nil
+	}
+	if !create {
+		return nil, fmt.Errorf("Cannot decrypt credentials: key %s not found", keyPath)
+	}
+
+	key, err := randomBytes(keyLength)
+	if err != nil {
+		return nil, err
+	}
+	err = writeConfigToFile(keyPath, key)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(rand.Reader, b)
+	if err != nil {
+		return nil, fmt.Errorf("Error generating random data: %v", err)
+	}
+	return b, nil
+}
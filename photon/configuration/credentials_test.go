@@ -0,0 +1,140 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/vmware/photon-controller-cli/photon/configuration"
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+var _ = Describe("Credentials", func() {
+	var configPath string
+
+	BeforeEach(func() {
+		var err error
+		UserConfigDir, err = ioutil.TempDir("", "credentials-test-")
+		Expect(err).To(BeNil())
+		configPath = path.Join(UserConfigDir, ".photon-config")
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(PassphraseEnvVar)
+		err := os.RemoveAll(UserConfigDir)
+		Expect(err).To(BeNil())
+	})
+
+	Describe("SaveConfig", func() {
+		It("keeps the token out of the config file", func() {
+			err := SaveConfig(&Configuration{CloudTarget: "http://localhost:9080", Token: "secret-token"})
+			Expect(err).To(BeNil())
+
+			data, err := ioutil.ReadFile(configPath)
+			Expect(err).To(BeNil())
+			Expect(string(data)).NotTo(ContainSubstring("secret-token"))
+
+			credentials, err := ioutil.ReadFile(path.Join(UserConfigDir, ".photon-credentials"))
+			Expect(err).To(BeNil())
+			Expect(string(credentials)).NotTo(ContainSubstring("secret-token"))
+
+			config, err := LoadConfig()
+			Expect(err).To(BeNil())
+			Expect(config.Token).To(Equal("secret-token"))
+		})
+
+		It("makes the config file readable only by the user", func() {
+			err := ioutil.WriteFile(configPath, []byte("{}"), 0644)
+			Expect(err).To(BeNil())
+
+			err = SaveConfig(&Configuration{CloudTarget: "http://localhost:9080"})
+			Expect(err).To(BeNil())
+
+			info, err := os.Stat(configPath)
+			Expect(err).To(BeNil())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+		})
+
+		It("keeps the token in the config file with the plaintext store", func() {
+			err := SaveContexts(&ContextsConfiguration{
+				CredentialStore: PlaintextStoreName,
+				Contexts: map[string]*Configuration{
+					DefaultContextName: {CloudTarget: "http://localhost:9080", Token: "plain-token"},
+				},
+			})
+			Expect(err).To(BeNil())
+
+			data, err := ioutil.ReadFile(configPath)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(ContainSubstring("plain-token"))
+		})
+	})
+
+	Describe("LoadConfig", func() {
+		It("moves tokens from an older config file to the credential store when it is next changed", func() {
+			err := ChangeConfigFileContents("{\"CloudTarget\": \"http://localhost:9080\", \"Token\": \"old-token\"}")
+			Expect(err).To(BeNil())
+
+			config, err := LoadConfig()
+			Expect(err).To(BeNil())
+			Expect(config.Token).To(Equal("old-token"))
+
+			data, err := ioutil.ReadFile(configPath)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(ContainSubstring("old-token"))
+
+			err = UpdateConfig(func(*Configuration) error { return nil })
+			Expect(err).To(BeNil())
+			data, err = ioutil.ReadFile(configPath)
+			Expect(err).To(BeNil())
+			Expect(string(data)).NotTo(ContainSubstring("old-token"))
+
+			config, err = LoadConfig()
+			Expect(err).To(BeNil())
+			Expect(config.Token).To(Equal("old-token"))
+		})
+
+		It("needs the passphrase the credentials were encrypted with only to use the tokens", func() {
+			os.Setenv(PassphraseEnvVar, "passphrase")
+			err := SaveConfig(&Configuration{CloudTarget: "http://localhost:9080", Token: "secret-token"})
+			Expect(err).To(BeNil())
+
+			config, err := LoadConfig()
+			Expect(err).To(BeNil())
+			Expect(config.Token).To(Equal("secret-token"))
+			Expect(config.CredentialsError()).To(BeNil())
+
+			os.Setenv(PassphraseEnvVar, "wrong")
+			config, err = LoadConfig()
+			Expect(err).To(BeNil())
+			Expect(config.Token).To(BeEmpty())
+			Expect(config.CredentialsError()).To(MatchError("Cannot decrypt credentials, check " + PassphraseEnvVar))
+
+			os.Unsetenv(PassphraseEnvVar)
+			config, err = LoadConfig()
+			Expect(err).To(BeNil())
+			Expect(config.CloudTarget).To(Equal("http://localhost:9080"))
+			Expect(config.CredentialsError()).To(
+				MatchError("Credentials are encrypted with a passphrase, set " + PassphraseEnvVar))
+
+			ConfigOverrides.Token = "override-token"
+			overridden := ApplyOverrides(config)
+			ConfigOverrides.Token = ""
+			Expect(overridden.Token).To(Equal("override-token"))
+			Expect(overridden.CredentialsError()).To(BeNil())
+
+			// Changing the config would lose the credentials
+			err = UpdateConfig(func(config *Configuration) error { return nil })
+			Expect(err).To(MatchError("Credentials are encrypted with a passphrase, set " + PassphraseEnvVar))
+		})
+	})
+})
@@ -0,0 +1,131 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+)
+
+const (
+	// How long to wait for another photon command to release the lock
+	lockTimeout = 10 * time.Second
+	// A lock older than this was left by a photon command that did not exit cleanly
+	staleLockAge      = 30 * time.Second
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+// Run f while holding the config file lock, so that photon commands running at the
+// same time do not overwrite each other's changes. The lock is a file created
+// exclusively next to the config file, which works the same way on every platform.
+func withConfigLock(f func() error) error {
+	userConfigDir, err := getUserConfigDirectory()
+	if err != nil {
+		return err
+	}
+	lockPath := path.Join(userConfigDir, ".photon-config.lock")
+
+	owner, err := acquireLock(lockPath)
+	if err != nil {
+		return err
+	}
+	defer releaseLock(lockPath, owner)
+
+	return f()
+}
+
+// Create the lock, and return its contents: the pid of this command and a random
+// nonce, which tell the lock of this command from a lock taken over by another one
+func acquireLock(lockPath string) (string, error) {
+	nonce := make([]byte, 8)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", fmt.Errorf("Error locking configuration: %v", err)
+	}
+	owner := fmt.Sprintf("%d %x\n", os.Getpid(), nonce)
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			_, err = file.WriteString(owner)
+			closeErr := file.Close()
+			if err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(lockPath)
+				return "", fmt.Errorf("Error locking configuration: %v", err)
+			}
+			return owner, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("Error locking configuration: %v", err)
+		}
+
+		if removeStaleLock(lockPath) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("Error locking configuration: %s is held by another photon command, "+
+				"remove it if no other photon command is running", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Remove the lock unless it is no longer the one created by this command, which
+// happens when it was held past staleLockAge and another command took it over
+func releaseLock(lockPath string, owner string) {
+	data, err := ioutil.ReadFile(lockPath)
+	if err == nil && string(data) == owner {
+		os.Remove(lockPath)
+	}
+}
+
+// Remove the lock if it is stale, and return whether it was removed. Only the photon
+// command holding the takeover file may do this: otherwise two commands could find the
+// same stale lock, and one of them remove the lock the other has just created.
+func removeStaleLock(lockPath string) bool {
+	if !isStaleLock(lockPath) {
+		return false
+	}
+
+	takeoverPath := lockPath + ".takeover"
+	if isStaleLock(takeoverPath) {
+		// Left by a command that did not exit while taking over the lock. The takeover
+		// is only tried again on the next attempt, after any other command that found
+		// the same stale takeover file has removed it too.
+		os.Remove(takeoverPath)
+		return false
+	}
+	file, err := os.OpenFile(takeoverPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return false
+	}
+	file.Close()
+	defer os.Remove(takeoverPath)
+
+	// Another command may have taken over the stale lock before this one got the
+	// takeover file, the lock is then no longer stale
+	if !isStaleLock(lockPath) {
+		return false
+	}
+	return os.Remove(lockPath) == nil
+}
+
+func isStaleLock(lockPath string) bool {
+	info, err := os.Stat(lockPath)
+	return err == nil && time.Since(info.ModTime()) > staleLockAge
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration_test
+
+import (
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/vmware/photon-controller-cli/photon/configuration"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+var _ = Describe("Lock", func() {
+	BeforeEach(func() {
+		var err error
+		UserConfigDir, err = ioutil.TempDir("", "lock-test-")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(UserConfigDir)
+		Expect(err).To(BeNil())
+	})
+
+	Describe("UpdateContexts", func() {
+		It("keeps the changes of concurrent updates", func() {
+			var wg sync.WaitGroup
+			errs := make(chan error, 10)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs <- UpdateContexts(func(contexts *ContextsConfiguration) error {
+						contexts.Contexts[fmt.Sprintf("ctx-%d", i)] = &Configuration{Token: "token"}
+						return nil
+					})
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				Expect(err).To(BeNil())
+			}
+
+			contexts, err := LoadContexts()
+			Expect(err).To(BeNil())
+			Expect(contexts.Contexts).To(HaveLen(10))
+			Expect(contexts.Version).To(Equal(ConfigVersion))
+
+			files, err := ioutil.ReadDir(UserConfigDir)
+			Expect(err).To(BeNil())
+			for _, f := range files {
+				Expect(f.Name()).NotTo(ContainSubstring(".tmp"))
+				Expect(f.Name()).NotTo(HaveSuffix(".lock"))
+			}
+		})
+
+		It("does not save the contexts when the update fails", func() {
+			err := SaveConfig(&Configuration{CloudTarget: "http://localhost:9080"})
+			Expect(err).To(BeNil())
+
+			err = UpdateConfig(func(config *Configuration) error {
+				config.CloudTarget = "http://localhost:9000"
+				return fmt.Errorf("failed")
+			})
+			Expect(err).To(MatchError("failed"))
+
+			config, err := LoadConfig()
+			Expect(err).To(BeNil())
+			Expect(config.CloudTarget).To(Equal("http://localhost:9080"))
+		})
+
+		It("takes over a lock left by a command that did not exit", func() {
+			lockPath := path.Join(UserConfigDir, ".photon-config.lock")
+			err := ioutil.WriteFile(lockPath, []byte("1\n"), 0600)
+			Expect(err).To(BeNil())
+			old := time.Now().Add(-time.Hour)
+			err = os.Chtimes(lockPath, old, old)
+			Expect(err).To(BeNil())
+
+			err = UpdateConfig(func(config *Configuration) error {
+				config.CloudTarget = "http://localhost:9080"
+				return nil
+			})
+			Expect(err).To(BeNil())
+
+			_, err = os.Stat(lockPath + ".takeover")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("takes over a lock whose takeover was left by a command that did not exit", func() {
+			lockPath := path.Join(UserConfigDir, ".photon-config.lock")
+			old := time.Now().Add(-time.Hour)
+			for _, p := range []string{lockPath, lockPath + ".takeover"} {
+				err := ioutil.WriteFile(p, []byte("1\n"), 0600)
+				Expect(err).To(BeNil())
+				err = os.Chtimes(p, old, old)
+				Expect(err).To(BeNil())
+			}
+
+			err := UpdateConfig(func(config *Configuration) error {
+				config.CloudTarget = "http://localhost:9080"
+				return nil
+			})
+			Expect(err).To(BeNil())
+
+			_, err = os.Stat(lockPath + ".takeover")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("does not remove the lock once another command has taken it over", func() {
+			lockPath := path.Join(UserConfigDir, ".photon-config.lock")
+			err := UpdateContexts(func(contexts *ContextsConfiguration) error {
+				// Another command found the lock stale, and replaced it with its own
+				return ioutil.WriteFile(lockPath, []byte("2 other-command\n"), 0600)
+			})
+			Expect(err).To(BeNil())
+
+			data, err := ioutil.ReadFile(lockPath)
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("2 other-command\n"))
+		})
+
+		It("takes over a stale lock only once", func() {
+			lockPath := path.Join(UserConfigDir, ".photon-config.lock")
+			err := ioutil.WriteFile(lockPath, []byte("1\n"), 0600)
+			Expect(err).To(BeNil())
+			old := time.Now().Add(-time.Hour)
+			err = os.Chtimes(lockPath, old, old)
+			Expect(err).To(BeNil())
+
+			var wg sync.WaitGroup
+			inLock := make(chan bool, 10)
+			errs := make(chan error, 10)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs <- UpdateContexts(func(contexts *ContextsConfiguration) error {
+						inLock <- true
+						defer func() { <-inLock }()
+						if len(inLock) > 1 {
+							return fmt.Errorf("lock held twice")
+						}
+						contexts.Contexts[fmt.Sprintf("ctx-%d", i)] = &Configuration{}
+						return nil
+					})
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				Expect(err).To(BeNil())
+			}
+		})
+	})
+
+	Describe("LoadContexts", func() {
+		It("returns no contexts for an empty config file", func() {
+			err := ChangeConfigFileContents("")
+			Expect(err).To(BeNil())
+
+			contexts, err := LoadContexts()
+			Expect(err).To(BeNil())
+			Expect(contexts.Contexts).To(BeEmpty())
+		})
+
+		It("fails on a config file written by a newer CLI", func() {
+			err := ChangeConfigFileContents("{\"Version\": 99, \"Contexts\": {}}")
+			Expect(err).To(BeNil())
+
+			_, err = LoadContexts()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("written by a newer photon CLI"))
+		})
+	})
+})
@@ -0,0 +1,78 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration
+
+import (
+	"strings"
+)
+
+// Values given with the global -target, -token, -tenant and -project flags, or else with
+// the PHOTON_TARGET, PHOTON_TOKEN, PHOTON_TENANT and PHOTON_PROJECT environment variables,
+// and with the global -proxy, -ca-file, -client-cert, -client-key, -timeout and -poll-interval flags.
+// They take precedence over the config file for one invocation and are never saved:
+// LoadConfig and SaveConfig only see the config file.
+type Overrides struct {
+	CloudTarget    string
+	Token          string
+	Tenant         string
+	Project        string
+	Proxy          string
+	CAFile         string
+	ClientCertFile string
+	ClientKeyFile  string
+
+	TaskTimeout      string
+	TaskPollInterval string
+}
+
+var ConfigOverrides Overrides
+
+// Returns a copy of the configuration with the target, token and connection overrides applied.
+// Tenant and project overrides are names, which commands resolve like -t and -p options.
+func ApplyOverrides(config *Configuration) *Configuration {
+	overridden := *config
+	if len(ConfigOverrides.CloudTarget) != 0 {
+		overridden.CloudTarget = ConfigOverrides.CloudTarget
+		if !sameTarget(overridden.CloudTarget, config.CloudTarget) {
+			// The saved tokens are for the saved target, and are not sent to another one
+			overridden.Token = ""
+			overridden.RefreshToken = ""
+			overridden.credentialsErr = nil
+		}
+	}
+	if len(ConfigOverrides.Token) != 0 {
+		// The saved refresh token can only renew the saved token
+		overridden.Token = ConfigOverrides.Token
+		overridden.RefreshToken = ""
+		overridden.credentialsErr = nil
+	}
+	if len(ConfigOverrides.Proxy) != 0 {
+		overridden.Proxy = ConfigOverrides.Proxy
+	}
+	if len(ConfigOverrides.CAFile) != 0 {
+		overridden.CAFile = ConfigOverrides.CAFile
+	}
+	if len(ConfigOverrides.ClientCertFile) != 0 {
+		overridden.ClientCertFile = ConfigOverrides.ClientCertFile
+		overridden.ClientKeyFile = ConfigOverrides.ClientKeyFile
+	}
+	if len(ConfigOverrides.TaskTimeout) != 0 {
+		overridden.TaskTimeout = ConfigOverrides.TaskTimeout
+	}
+	if len(ConfigOverrides.TaskPollInterval) != 0 {
+		overridden.TaskPollInterval = ConfigOverrides.TaskPollInterval
+	}
+	return &overridden
+}
+
+// Tells if two target URLs are the same, ignoring a trailing slash
+func sameTarget(target1 string, target2 string) bool {
+	return strings.TrimRight(target1, "/") == strings.TrimRight(target2, "/")
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+var _ = Describe("Overrides", func() {
+	var config *Configuration
+
+	BeforeEach(func() {
+		config = &Configuration{
+			CloudTarget:  "http://localhost:9080",
+			Token:        "saved-token",
+			RefreshToken: "saved-refresh-token",
+		}
+	})
+
+	AfterEach(func() {
+		ConfigOverrides = Overrides{}
+	})
+
+	Describe("ApplyOverrides", func() {
+		It("keeps the saved tokens for the saved target", func() {
+			ConfigOverrides.CloudTarget = "http://localhost:9080/"
+			overridden := ApplyOverrides(config)
+			Expect(overridden.Token).To(Equal("saved-token"))
+			Expect(overridden.RefreshToken).To(Equal("saved-refresh-token"))
+		})
+
+		It("does not send the saved tokens to another target", func() {
+			ConfigOverrides.CloudTarget = "http://other:9080"
+			overridden := ApplyOverrides(config)
+			Expect(overridden.CloudTarget).To(Equal("http://other:9080"))
+			Expect(overridden.Token).To(BeEmpty())
+			Expect(overridden.RefreshToken).To(BeEmpty())
+			Expect(config.Token).To(Equal("saved-token"))
+		})
+
+		It("uses the token given with another target", func() {
+			ConfigOverrides.CloudTarget = "http://other:9080"
+			ConfigOverrides.Token = "override-token"
+			overridden := ApplyOverrides(config)
+			Expect(overridden.Token).To(Equal("override-token"))
+			Expect(overridden.RefreshToken).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,302 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings of the config file are named by keys: the name of a field of
+// ContextsConfiguration (e.g. CurrentContext), the name of a field of the
+// configuration of the context in use (e.g. CloudTarget or Tenant.Name), or
+// Contexts.<name>.<field> for the configuration of any context. Keys are found by
+// reflection, so new fields are settings too, and are not case sensitive.
+
+// Value shown instead of a secret
+const RedactedValue = "<redacted>"
+
+// Fields holding secrets, which are redacted
+var secretFields = []string{"Token", "RefreshToken"}
+
+// Fields that cannot be set
+var readOnlyFields = []string{"Version", "Contexts"}
+
+// A single setting and its value
+type Setting struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// Get the path of the config file
+func GetConfigFilePath() (string, error) {
+	return getConfigurationFilePath()
+}
+
+// Returns a copy of the contexts with the secrets replaced by RedactedValue
+func (contexts *ContextsConfiguration) Redacted() *ContextsConfiguration {
+	redacted := *contexts
+	redacted.Contexts = map[string]*Configuration{}
+	for name, config := range contexts.Contexts {
+		configCopy := *config
+		value := reflect.ValueOf(&configCopy).Elem()
+		for _, field := range secretFields {
+			secret := value.FieldByName(field)
+			if secret.String() != "" {
+				secret.SetString(RedactedValue)
+			}
+		}
+		redacted.Contexts[name] = &configCopy
+	}
+	return &redacted
+}
+
+// Returns every setting with its value, with the contexts sorted by name. Settings
+// of a nested configuration that is not set, such as Tenant, have empty values.
+func (contexts *ContextsConfiguration) Settings() []Setting {
+	settings := []Setting{}
+	var list func(prefix string, value reflect.Value)
+	list = func(prefix string, value reflect.Value) {
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value = reflect.New(value.Type().Elem())
+			}
+			value = value.Elem()
+		}
+		switch value.Kind() {
+		case reflect.Struct:
+			for i := 0; i < value.NumField(); i++ {
+				if value.Type().Field(i).PkgPath == "" {
+					list(joinKey(prefix, value.Type().Field(i).Name), value.Field(i))
+				}
+			}
+		case reflect.Map:
+			for _, name := range contexts.Names() {
+				list(joinKey(prefix, name), value.MapIndex(reflect.ValueOf(name)))
+			}
+		default:
+			settings = append(settings, Setting{Key: prefix, Value: value.Interface()})
+		}
+	}
+	list("", reflect.ValueOf(contexts))
+	return settings
+}
+
+// Get the value of a setting; a nested configuration such as Tenant is returned as is
+func (contexts *ContextsConfiguration) GetValue(key string) (interface{}, error) {
+	_, value, err := contexts.findSetting(key, false)
+	if err != nil {
+		return nil, err
+	}
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return nil, nil
+	}
+	return value.Interface(), nil
+}
+
+// Set a setting to the given value, converted to the type of the setting
+func (contexts *ContextsConfiguration) SetValue(key string, text string) error {
+	path, value, err := contexts.findSetting(key, true)
+	if err != nil {
+		return err
+	}
+	err = checkWritable(path)
+	if err != nil {
+		return err
+	}
+	err = contexts.validateSetting(path, text)
+	if err != nil {
+		return err
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("Invalid value '%s' for '%s', should be true or false", text, key)
+		}
+		value.SetBool(b)
+	case reflect.Int:
+		i, err := strconv.Atoi(text)
+		if err != nil {
+			return fmt.Errorf("Invalid value '%s' for '%s', should be a number", text, key)
+		}
+		value.SetInt(int64(i))
+	default:
+		return fmt.Errorf("'%s' has several settings, set them one by one, e.g. %s.%s",
+			key, key, firstFieldName(value.Type()))
+	}
+	return nil
+}
+
+// Reset a setting to its default value; a nested configuration such as Tenant is removed
+func (contexts *ContextsConfiguration) UnsetValue(key string) error {
+	path, value, err := contexts.findSetting(key, false)
+	if err != nil {
+		return err
+	}
+	err = checkWritable(path)
+	if err != nil {
+		return err
+	}
+	if value.CanSet() {
+		value.Set(reflect.Zero(value.Type()))
+	}
+	return nil
+}
+
+// Find a setting from its key. Returns the path to the setting, with the field names
+// as in the structs, and the value, which can be set. Nested configurations and the
+// context in use are created if create is true; otherwise the value of a setting
+// within a missing nested configuration is a zero value, which cannot be saved.
+func (contexts *ContextsConfiguration) findSetting(key string, create bool) ([]string, reflect.Value, error) {
+	parts := strings.Split(key, ".")
+	if _, ok := findField(reflect.TypeOf(*contexts), parts[0]); !ok {
+		if _, ok := findField(reflect.TypeOf(Configuration{}), parts[0]); !ok {
+			return nil, reflect.Value{}, unknownKeyError(key)
+		}
+		parts = append([]string{"Contexts", contexts.ActiveContextName()}, parts...)
+	}
+
+	path := []string{}
+	value := reflect.ValueOf(contexts).Elem()
+	for _, part := range parts {
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				if create {
+					value.Set(reflect.New(value.Type().Elem()))
+				} else {
+					value = reflect.New(value.Type().Elem())
+				}
+			}
+			value = value.Elem()
+		}
+
+		switch value.Kind() {
+		case reflect.Struct:
+			field, ok := findField(value.Type(), part)
+			if !ok {
+				return nil, reflect.Value{}, unknownKeyError(key)
+			}
+			path = append(path, field.Name)
+			value = value.FieldByIndex(field.Index)
+		case reflect.Map:
+			path = append(path, part)
+			config, err := contexts.findContext(part, create)
+			if err != nil {
+				return nil, reflect.Value{}, err
+			}
+			value = reflect.ValueOf(config)
+		default:
+			return nil, reflect.Value{}, unknownKeyError(key)
+		}
+	}
+	return path, value, nil
+}
+
+// Find a context for a setting. Settings of the context in use can be read even if
+// the context does not exist yet, as long as it was not named with -context.
+func (contexts *ContextsConfiguration) findContext(name string, create bool) (*Configuration, error) {
+	config, ok := contexts.Contexts[name]
+	if ok {
+		return config, nil
+	}
+	if name != contexts.ActiveContextName() || len(ContextOverride) != 0 {
+		return nil, fmt.Errorf("Context '%s' does not exist", name)
+	}
+
+	config = &Configuration{}
+	if create {
+		if contexts.Contexts == nil {
+			contexts.Contexts = map[string]*Configuration{}
+		}
+		contexts.Contexts[name] = config
+		if len(contexts.CurrentContext) == 0 {
+			contexts.CurrentContext = name
+		}
+	}
+	return config, nil
+}
+
+// Check the value of a setting that only accepts some values
+func (contexts *ContextsConfiguration) validateSetting(path []string, value string) error {
+	switch strings.Join(path, ".") {
+	case "CurrentContext":
+		if _, ok := contexts.Contexts[value]; !ok {
+			return fmt.Errorf("Context '%s' does not exist", value)
+		}
+	case "CredentialStore":
+		_, err := GetCredentialStore(value)
+		return err
+	}
+	if len(path) == 3 && path[0] == "Contexts" {
+		switch path[2] {
+		case "TaskTimeout", "TaskPollInterval":
+			_, err := ParseDurationSetting(path[2], value)
+			return err
+		}
+	}
+	return nil
+}
+
+// Parses a duration setting such as TaskTimeout, which must be positive
+func ParseDurationSetting(key string, value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("Invalid %s '%s', should be a duration such as 90s, 30m or 2h", key, value)
+	}
+	return duration, nil
+}
+
+func checkWritable(path []string) error {
+	for _, field := range readOnlyFields {
+		if path[len(path)-1] == field {
+			return fmt.Errorf("'%s' cannot be changed", strings.Join(path, "."))
+		}
+	}
+	if len(path) == 2 && path[0] == "Contexts" {
+		return fmt.Errorf("'%s' is a context, use 'target set --name' or 'target delete'", strings.Join(path, "."))
+	}
+	return nil
+}
+
+// Finds an exported field by its name, ignoring case
+func findField(t reflect.Type, name string) (reflect.StructField, bool) {
+	field, ok := t.FieldByNameFunc(func(fieldName string) bool {
+		return strings.EqualFold(fieldName, name)
+	})
+	return field, ok && field.PkgPath == ""
+}
+
+func firstFieldName(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.NumField() == 0 {
+		return "<name>"
+	}
+	return t.Field(0).Name
+}
+
+func joinKey(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func unknownKeyError(key string) error {
+	return fmt.Errorf("Unknown configuration key '%s', see 'config view' for the keys", key)
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package configuration_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/vmware/photon-controller-cli/photon/configuration"
+)
+
+var _ = Describe("Settings", func() {
+	var contexts *ContextsConfiguration
+
+	BeforeEach(func() {
+		contexts = &ContextsConfiguration{
+			CurrentContext: "dev",
+			Contexts: map[string]*Configuration{
+				"dev":  {CloudTarget: "http://dev:9000", Token: "dev-token"},
+				"prod": {CloudTarget: "http://prod:9000"},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		ContextOverride = ""
+	})
+
+	It("gets settings of the context in use or of a named context", func() {
+		value, err := contexts.GetValue("CloudTarget")
+		Expect(err).To(BeNil())
+		Expect(value).To(Equal("http://dev:9000"))
+
+		value, err = contexts.GetValue("Contexts.prod.CloudTarget")
+		Expect(err).To(BeNil())
+		Expect(value).To(Equal("http://prod:9000"))
+
+		ContextOverride = "prod"
+		value, err = contexts.GetValue("cloudtarget")
+		Expect(err).To(BeNil())
+		Expect(value).To(Equal("http://prod:9000"))
+	})
+
+	It("creates nested configurations when setting them", func() {
+		err := contexts.SetValue("Project.ID", "project-id")
+		Expect(err).To(BeNil())
+		Expect(contexts.Contexts["dev"].Project.ID).To(Equal("project-id"))
+
+		err = contexts.UnsetValue("Project")
+		Expect(err).To(BeNil())
+		Expect(contexts.Contexts["dev"].Project).To(BeNil())
+	})
+
+	It("rejects unknown keys and invalid values", func() {
+		_, err := contexts.GetValue("Tenant.Nmae")
+		Expect(err).To(MatchError("Unknown configuration key 'Tenant.Nmae', see 'config view' for the keys"))
+
+		err = contexts.SetValue("CredentialStore", "keychain")
+		Expect(err).To(HaveOccurred())
+
+		err = contexts.SetValue("CurrentContext", "staging")
+		Expect(err).To(MatchError("Context 'staging' does not exist"))
+
+		err = contexts.SetValue("Contexts.staging.CloudTarget", "http://staging:9000")
+		Expect(err).To(MatchError("Context 'staging' does not exist"))
+
+		err = contexts.SetValue("TaskTimeout", "2 hours")
+		Expect(err).To(MatchError("Invalid TaskTimeout '2 hours', should be a duration such as 90s, 30m or 2h"))
+
+		err = contexts.SetValue("Contexts.prod.TaskPollInterval", "2s")
+		Expect(err).To(BeNil())
+		Expect(contexts.Contexts["prod"].TaskPollInterval).To(Equal("2s"))
+	})
+
+	It("lists every setting with the secrets redacted", func() {
+		settings := contexts.Redacted().Settings()
+		Expect(settings).To(ContainElement(Setting{Key: "Contexts.dev.Token", Value: RedactedValue}))
+		Expect(settings).To(ContainElement(Setting{Key: "Contexts.prod.Token", Value: ""}))
+		Expect(settings).To(ContainElement(Setting{Key: "Contexts.prod.Tenant.Name", Value: ""}))
+		Expect(contexts.Contexts["dev"].Token).To(Equal("dev-token"))
+	})
+})
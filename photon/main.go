@@ -14,6 +14,7 @@ import (
 	"github.com/codegangsta/cli"
 	"github.com/vmware/photon-controller-cli/photon/client"
 	"github.com/vmware/photon-controller-cli/photon/command"
+	"github.com/vmware/photon-controller-cli/photon/configuration"
 	"github.com/vmware/photon-controller-cli/photon/utils"
 	"os"
 )
@@ -26,7 +27,7 @@ func main() {
 	err := app.Run(os.Args)
 	if err != nil {
 		fmt.Println(err.Error())
-		os.Exit(1)
+		os.Exit(command.ExitUsage)
 	}
 }
 
@@ -46,13 +47,97 @@ func BuildApp() *cli.App {
 		},
 		cli.StringFlag{
 			Name:  "output, o",
-			Usage: "Select output format",
+			Usage: "Select output format (json, yaml, csv, tsv, jsonpath=, custom-columns=, template=, template-file=)",
+		},
+		cli.BoolFlag{
+			Name:  "no-headers",
+			Usage: "Don't print headers with custom-columns, csv or tsv output",
+		},
+		cli.StringFlag{
+			Name:  "context",
+			Usage: "Use the named target context instead of the current one",
+		},
+		cli.StringFlag{
+			Name:   "target",
+			Usage:  "API target endpoint, overrides the config file",
+			EnvVar: "PHOTON_TARGET",
+		},
+		cli.StringFlag{
+			Name:   "token",
+			Usage:  "Access token, overrides the config file (prefer PHOTON_TOKEN to keep it out of the process list)",
+			EnvVar: "PHOTON_TOKEN",
+		},
+		cli.StringFlag{
+			Name:   "tenant",
+			Usage:  "Tenant name, overrides the config file",
+			EnvVar: "PHOTON_TENANT",
+		},
+		cli.StringFlag{
+			Name:   "project",
+			Usage:  "Project name, overrides the config file",
+			EnvVar: "PHOTON_PROJECT",
+		},
+		cli.StringFlag{
+			Name:  "proxy",
+			Usage: "Proxy URL for the API target, instead of the one given by HTTPS_PROXY and NO_PROXY",
+		},
+		cli.StringFlag{
+			Name:   "ca-file",
+			Usage:  "PEM file with certificate authorities to trust, in addition to the system ones",
+			EnvVar: "PHOTON_CA_FILE",
+		},
+		cli.StringFlag{
+			Name:   "client-cert",
+			Usage:  "PEM file with a client certificate to present to the API target",
+			EnvVar: "PHOTON_CLIENT_CERT",
+		},
+		cli.StringFlag{
+			Name:   "client-key",
+			Usage:  "PEM file with the private key of the client certificate, if not in the certificate file",
+			EnvVar: "PHOTON_CLIENT_KEY",
+		},
+		cli.BoolFlag{
+			Name:  "async, no-wait",
+			Usage: "Don't wait for the tasks started by commands, print their IDs instead (see 'task wait')",
+		},
+		cli.BoolFlag{
+			Name:  "trace, debug-http",
+			Usage: "Write the HTTP requests and responses, with equivalent curl commands, to the standard error",
+		},
+		cli.DurationFlag{
+			Name:   "timeout",
+			Usage:  "How long to wait for tasks before giving up, e.g. 2h (default 30m, or the TaskTimeout of the target, longer for clusters and deployments)",
+			EnvVar: "PHOTON_TIMEOUT",
+		},
+		cli.DurationFlag{
+			Name:   "poll-interval",
+			Usage:  "How often to poll tasks at first, e.g. 5s (default 500ms, or the TaskPollInterval of the target)",
+			EnvVar: "PHOTON_POLL_INTERVAL",
+		},
+		cli.IntFlag{
+			Name:   "retries",
+			Value:  client.DefaultRetries,
+			Usage:  "Number of times to retry read requests when the API target is unavailable",
+			EnvVar: "PHOTON_RETRIES",
+		},
+		cli.DurationFlag{
+			Name:   "retry-max-wait",
+			Value:  client.DefaultRetryMaxWait,
+			Usage:  "Maximum total time to wait between retries, e.g. 30s or 5m",
+			EnvVar: "PHOTON_RETRY_MAX_WAIT",
+		},
+		cli.StringFlag{
+			Name:   "config-dir",
+			Usage:  "Directory with the config file and trusted certificates, instead of ~/.photon-cli",
+			EnvVar: "PHOTON_CONFIG_DIR",
 		},
 	}
 	app.Commands = []cli.Command{
 		command.GetAuthCommand(),
 		command.GetSystemCommand(),
 		command.GetTargetCommand(),
+		command.GetConfigCommand(),
+		command.GetCertsCommand(),
 		command.GetTenantsCommand(),
 		command.GetHostsCommand(),
 		command.GetDeploymentsCommand(),
@@ -68,6 +153,43 @@ func BuildApp() *cli.App {
 		command.GetAvailabilityZonesCommand(),
 	}
 	app.Before = func(c *cli.Context) error {
+		configuration.ContextOverride = c.GlobalString("context")
+		configuration.ConfigOverrides = configuration.Overrides{
+			CloudTarget: c.GlobalString("target"),
+			Token:       c.GlobalString("token"),
+			Tenant:      c.GlobalString("tenant"),
+			Project:     c.GlobalString("project"),
+
+			Proxy:          c.GlobalString("proxy"),
+			CAFile:         c.GlobalString("ca-file"),
+			ClientCertFile: c.GlobalString("client-cert"),
+			ClientKeyFile:  c.GlobalString("client-key"),
+		}
+		client.Retries = client.RetryOptions{
+			MaxRetries: c.GlobalInt("retries"),
+			MaxWait:    c.GlobalDuration("retry-max-wait"),
+		}
+		if c.GlobalBool("trace") {
+			client.Trace = os.Stderr
+		}
+		if client.Retries.MaxRetries < 0 || client.Retries.MaxWait < 0 {
+			return fmt.Errorf("--retries and --retry-max-wait cannot be negative")
+		}
+		if timeout := c.GlobalDuration("timeout"); timeout != 0 {
+			if timeout < 0 {
+				return fmt.Errorf("--timeout cannot be negative")
+			}
+			configuration.ConfigOverrides.TaskTimeout = timeout.String()
+		}
+		if interval := c.GlobalDuration("poll-interval"); interval != 0 {
+			if interval < 0 {
+				return fmt.Errorf("--poll-interval cannot be negative")
+			}
+			configuration.ConfigOverrides.TaskPollInterval = interval.String()
+		}
+		if configDir := c.GlobalString("config-dir"); configDir != "" {
+			configuration.UserConfigDir = configDir
+		}
 		logFile := c.GlobalString("log-file")
 		if logFile != "" {
 			return client.InitializeLogging(logFile)
@@ -0,0 +1,122 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package utils
+
+/**
+ * Custom columns output prints objects as a table with the columns chosen by the user:
+ *   --output custom-columns=NAME:.name,FLAVOR:.flavor,HOST:.host
+ *
+ * Each column is a header and a JSONPath expression (see jsonpath.go) evaluated against
+ * each object. A list of objects prints one row per object, a single object prints one row.
+ */
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// One user-selected column: the header to print and the path of the value
+type customColumn struct {
+	header string
+	path   []jsonPathElement
+}
+
+// Parses a column specification such as NAME:.name,FLAVOR:.flavor
+func parseCustomColumns(spec string) ([]customColumn, error) {
+	columns := []customColumn{}
+	for _, columnSpec := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(columnSpec), ":", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return nil, fmt.Errorf("invalid custom column '%s', should be: <header>:<path>", columnSpec)
+		}
+
+		expression := strings.TrimSuffix(strings.TrimPrefix(parts[1], "{"), "}")
+		if !strings.HasPrefix(expression, ".") && !strings.HasPrefix(expression, "[") {
+			return nil, fmt.Errorf("invalid path '%s' for custom column '%s': must start with '.'", parts[1], parts[0])
+		}
+		path, err := parseJsonPathExpression(expression)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, customColumn{header: parts[0], path: path})
+	}
+	return columns, nil
+}
+
+// Returns the objects to print as rows: the items of a list, or the object itself
+func customColumnRows(data interface{}) []interface{} {
+	if list, ok := data.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{data}
+}
+
+// Returns the text of one cell. Missing values are printed as "-" and multiple
+// values (e.g. from a wildcard) are separated by commas.
+func customColumnCell(column customColumn, row interface{}) (string, error) {
+	values := evalJsonPath(column.path, row)
+	cells := []string{}
+	for _, value := range values {
+		text, err := jsonValueToString(value)
+		if err != nil {
+			return "", err
+		}
+		if len(text) != 0 {
+			cells = append(cells, text)
+		}
+	}
+	if len(cells) == 0 {
+		return "-", nil
+	}
+	return strings.Join(cells, ","), nil
+}
+
+// Output an object or list of objects as a table with user-selected columns
+func formatObjectCustomColumns(o interface{}, spec string, noHeaders bool, w io.Writer) {
+	columns, err := parseCustomColumns(spec)
+	if err != nil {
+		fmt.Fprintf(w, "Cannot parse custom columns: %s", err)
+		return
+	}
+
+	data, err := toJsonValue(o)
+	if err != nil {
+		fmt.Fprintf(w, "Cannot convert output to JSON: %s", err)
+		return
+	}
+
+	tw := new(tabwriter.Writer)
+	tw.Init(w, 4, 4, 2, ' ', 0)
+	if !noHeaders {
+		headers := []string{}
+		for _, column := range columns {
+			headers = append(headers, column.header)
+		}
+		fmt.Fprintf(tw, "%s\n", strings.Join(headers, "\t"))
+	}
+	for _, row := range customColumnRows(data) {
+		cells := []string{}
+		for _, column := range columns {
+			cell, err := customColumnCell(column, row)
+			if err != nil {
+				fmt.Fprintf(w, "Cannot format custom columns: %s", err)
+				return
+			}
+			cells = append(cells, cell)
+		}
+		fmt.Fprintf(tw, "%s\n", strings.Join(cells, "\t"))
+	}
+	err = tw.Flush()
+	if err != nil {
+		fmt.Fprintf(w, "Cannot format custom columns: %s", err)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package utils
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/vmware/photon-controller-go-sdk/photon"
+)
+
+func TestFormatObjectsCustomColumns(t *testing.T) {
+	hosts := []photon.Host{
+		{ID: "host-1", Address: "10.0.0.1", State: "READY", Tags: []string{"CLOUD", "MGMT"}},
+		{ID: "host-2", Address: "10.0.0.2", State: "MAINTENANCE"},
+	}
+
+	var output bytes.Buffer
+	FormatObjects(hosts, &output, newOutputContext(t, "custom-columns=ID:.id,IP:.address,TAGS:.usageTags[*]"))
+
+	expected := "ID      IP        TAGS\n" +
+		"host-1  10.0.0.1  CLOUD,MGMT\n" +
+		"host-2  10.0.0.2  -\n"
+	if output.String() != expected {
+		t.Errorf("Unexpected custom columns output, expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestFormatObjectCustomColumnsNoHeaders(t *testing.T) {
+	globalFlags := flag.NewFlagSet("global-flags", flag.ContinueOnError)
+	globalFlags.String("output", "", "output")
+	globalFlags.Bool("no-headers", false, "no-headers")
+	err := globalFlags.Parse([]string{"--output=custom-columns=NAME:.name,FLAVOR:{.flavor}", "--no-headers"})
+	if err != nil {
+		t.Error(err)
+	}
+	globalCtx := cli.NewContext(nil, globalFlags, nil)
+	cxt := cli.NewContext(nil, flag.NewFlagSet("command-flags", flag.ContinueOnError), globalCtx)
+
+	err = ValidateArgs(cxt)
+	if err != nil {
+		t.Errorf("Not expecting error validating custom columns: %s", err)
+	}
+
+	var output bytes.Buffer
+	FormatObject(photon.VM{Name: "fake-vm", Flavor: "small"}, &output, cxt)
+	if output.String() != "fake-vm  small\n" {
+		t.Errorf("Unexpected custom columns output: '%s'", output.String())
+	}
+}
+
+func TestParseCustomColumnsErrors(t *testing.T) {
+	specs := []string{
+		"NAME",
+		"NAME:",
+		":.name",
+		"NAME:name",
+		"NAME:.name,",
+		"NAME:.items[",
+	}
+
+	for _, spec := range specs {
+		_, err := parseCustomColumns(spec)
+		if err == nil {
+			t.Errorf("Expected error parsing custom columns '%s'", spec)
+		}
+	}
+}
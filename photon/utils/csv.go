@@ -0,0 +1,221 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package utils
+
+/**
+ * CSV and TSV output print one row per object with a header row, so that lists can be
+ * opened directly in a spreadsheet.
+ *
+ * Nested fields are flattened into columns named after their JSON path:
+ * - objects and maps: one column per field, e.g. metadata.owner
+ * - lists of objects: one set of columns per element, e.g. attachedDisks.0.name
+ * - lists of values: a single column with the values separated by ';', e.g. usageTags
+ * - quota line items (cost, limits, usage): two columns per key, with the value and
+ *   the unit, e.g. limits.vm.memory and limits.vm.memory.unit
+ *
+ * Columns are in the order of the JSON output. A column that only some of the objects
+ * have is empty for the others.
+ */
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// One flattened field of an object
+type flatField struct {
+	column string
+	value  string
+}
+
+// Flattens a value decoded by decodeOrderedJson into a list of columns and values
+func flattenValue(prefix string, value interface{}) []flatField {
+	switch typed := value.(type) {
+	case nil:
+		return nil
+	case yaml.MapSlice:
+		fields := []flatField{}
+		for _, item := range typed {
+			fields = append(fields, flattenValue(joinColumn(prefix, fmt.Sprint(item.Key)), item.Value)...)
+		}
+		return fields
+	case []interface{}:
+		if len(typed) == 0 {
+			return nil
+		}
+		if isQuotaList(typed) {
+			fields := []flatField{}
+			for _, item := range typed {
+				quota := mapSliceToMap(item.(yaml.MapSlice))
+				column := joinColumn(prefix, fmt.Sprint(quota["key"]))
+				fields = append(fields,
+					flatField{column, fmt.Sprint(quota["value"])},
+					flatField{column + ".unit", fmt.Sprint(quota["unit"])})
+			}
+			return fields
+		}
+		if _, ok := typed[0].(yaml.MapSlice); ok {
+			fields := []flatField{}
+			for i, item := range typed {
+				fields = append(fields, flattenValue(joinColumn(prefix, fmt.Sprint(i)), item)...)
+			}
+			return fields
+		}
+		values := []string{}
+		for _, item := range typed {
+			values = append(values, flatScalar(item))
+		}
+		return []flatField{{prefix, strings.Join(values, ";")}}
+	default:
+		return []flatField{{prefix, flatScalar(typed)}}
+	}
+}
+
+func joinColumn(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// Converts a scalar to text; anything else (e.g. a list within a list) is written as JSON
+func flatScalar(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case int64, float64, bool:
+		return fmt.Sprint(typed)
+	default:
+		jsonBytes, err := json.Marshal(typed)
+		if err != nil {
+			return fmt.Sprint(typed)
+		}
+		return string(jsonBytes)
+	}
+}
+
+// Tells if every element of the list looks like a photon.QuotaLineItem
+func isQuotaList(list []interface{}) bool {
+	for _, item := range list {
+		object, ok := item.(yaml.MapSlice)
+		if !ok || len(object) != 3 {
+			return false
+		}
+		quota := mapSliceToMap(object)
+		for _, key := range []string{"key", "value", "unit"} {
+			if _, ok := quota[key]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func mapSliceToMap(object yaml.MapSlice) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, item := range object {
+		result[fmt.Sprint(item.Key)] = item.Value
+	}
+	return result
+}
+
+// Adds the columns of a row to the list of columns. A new column is placed right after
+// the previous column of the row, so that e.g. attachedDisks.1.name ends up next to
+// attachedDisks.0.name even if only a later row has a second disk.
+func mergeColumns(columns []string, fields []flatField) []string {
+	position := map[string]int{}
+	for i, column := range columns {
+		position[column] = i
+	}
+
+	previous := -1
+	for _, field := range fields {
+		if i, ok := position[field.column]; ok {
+			previous = i
+			continue
+		}
+		insertAt := previous + 1
+		columns = append(columns, "")
+		copy(columns[insertAt+1:], columns[insertAt:])
+		columns[insertAt] = field.column
+		for i, column := range columns {
+			position[column] = i
+		}
+		previous = insertAt
+	}
+	return columns
+}
+
+// Flattens an object into field names and values, with the same columns as CSV output
+func FlattenObject(o interface{}) (columns []string, values []string, err error) {
+	data, err := toOrderedJsonValue(o)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, field := range flattenValue("", data) {
+		columns = append(columns, field.column)
+		values = append(values, field.value)
+	}
+	return columns, values, nil
+}
+
+// Output an object or a list of objects as comma or tab separated values
+func formatObjectDelimited(o interface{}, separator rune, noHeaders bool, w io.Writer) {
+	data, err := toOrderedJsonValue(o)
+	if err != nil {
+		fmt.Fprintf(w, "Cannot convert output to JSON: %s", err)
+		return
+	}
+
+	columns := []string{}
+	rows := []map[string]string{}
+	for _, row := range customColumnRows(data) {
+		fields := flattenValue("", row)
+		columns = mergeColumns(columns, fields)
+		values := map[string]string{}
+		for _, field := range fields {
+			values[field.column] = field.value
+		}
+		rows = append(rows, values)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = separator
+	if !noHeaders && len(columns) != 0 {
+		err = writer.Write(columns)
+		if err != nil {
+			fmt.Fprintf(w, "Cannot write output: %s", err)
+			return
+		}
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		err = writer.Write(record)
+		if err != nil {
+			fmt.Fprintf(w, "Cannot write output: %s", err)
+			return
+		}
+	}
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		fmt.Fprintf(w, "Cannot write output: %s", err)
+	}
+}
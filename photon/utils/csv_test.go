@@ -0,0 +1,74 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vmware/photon-controller-go-sdk/photon"
+)
+
+func TestFormatObjectsCsv(t *testing.T) {
+	vms := []photon.VM{
+		{
+			ID:            "vm-1",
+			Name:          "first",
+			Flavor:        "small",
+			Cost:          []photon.QuotaLineItem{{Key: "vm.memory", Value: 2, Unit: "GB"}},
+			AttachedDisks: []photon.AttachedDisk{{Name: "boot", Flavor: "disk", BootDisk: true}},
+			Tags:          []string{"web", "prod"},
+		},
+		{
+			ID:     "vm-2",
+			Name:   "second, with comma",
+			Flavor: "large",
+			Cost:   []photon.QuotaLineItem{{Key: "vm.memory", Value: 0.5, Unit: "GB"}},
+			AttachedDisks: []photon.AttachedDisk{
+				{Name: "boot", Flavor: "disk", BootDisk: true},
+				{Name: "data", Flavor: "disk", CapacityGB: 20},
+			},
+		},
+	}
+
+	var output bytes.Buffer
+	FormatObjects(vms, &output, newOutputContext(t, "csv"))
+
+	expected := "cost.vm.memory,cost.vm.memory.unit,kind," +
+		"attachedDisks.0.flavor,attachedDisks.0.kind,attachedDisks.0.name,attachedDisks.0.state,attachedDisks.0.bootDisk," +
+		"attachedDisks.1.flavor,attachedDisks.1.kind,attachedDisks.1.capacityGb,attachedDisks.1.name," +
+		"attachedDisks.1.state,attachedDisks.1.bootDisk,tags,flavor,name,state,id\n" +
+		"2,GB,,disk,,boot,,true,,,,,,,web;prod,small,first,,vm-1\n" +
+		"0.5,GB,,disk,,boot,,true,disk,,20,data,,false,,large,\"second, with comma\",,vm-2\n"
+	if output.String() != expected {
+		t.Errorf("Unexpected CSV output, expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestFormatObjectsTsv(t *testing.T) {
+	hosts := []photon.Host{
+		{ID: "host-1", Address: "10.0.0.1", Tags: []string{"CLOUD", "MGMT"}, Metadata: map[string]string{"a": "1"}},
+	}
+
+	var output bytes.Buffer
+	FormatObjects(hosts, &output, newOutputContext(t, "tsv"))
+
+	expected := "username\tpassword\taddress\tkind\tid\tusageTags\tmetadata.a\tselfLink\tstate\tesxVersion\n" +
+		"\t\t10.0.0.1\t\thost-1\tCLOUD;MGMT\t1\t\t\t\n"
+	if output.String() != expected {
+		t.Errorf("Unexpected TSV output, expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+
+	output.Reset()
+	FormatObjects([]photon.Host{}, &output, newOutputContext(t, "tsv"))
+	if output.String() != "" {
+		t.Errorf("Unexpected TSV output for an empty list: '%s'", output.String())
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package utils
+
+/**
+ * Client-side filtering and sorting of lists returned by the API.
+ *
+ * A filter is a comma-separated list of conditions, all of which must match:
+ *   state=STARTED,flavor~=large
+ * Each condition is <field><operator><value>, where the field is a JSONPath expression
+ * (the leading '.' is optional) and the operator is one of:
+ * - =  the field equals the value
+ * - != the field does not equal the value
+ * - ~= the field matches the value as a regular expression
+ * If the field is a list (e.g. usageTags), the condition matches if any element matches.
+ *
+ * Sorting takes a JSONPath expression, e.g. .name, and sorts numbers numerically and
+ * everything else alphabetically. Objects with equal values keep the order of the server.
+ *
+ * Filtering and sorting happen on the SDK objects before they are printed, so they behave
+ * the same way for every output format.
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// One condition of a filter
+type filterCondition struct {
+	field    string
+	path     []jsonPathElement
+	operator string
+	value    string
+	regexp   *regexp.Regexp
+}
+
+// Parses a filter such as state=STARTED,flavor~=large
+func parseFilter(filter string) ([]filterCondition, error) {
+	conditions := []filterCondition{}
+	if len(strings.TrimSpace(filter)) == 0 {
+		return conditions, nil
+	}
+
+	for _, conditionSpec := range strings.Split(filter, ",") {
+		equals := strings.Index(conditionSpec, "=")
+		if equals <= 0 {
+			return nil, fmt.Errorf("Error parsing filter '%s', should be: <field>=<value>, <field>!=<value> or <field>~=<value>", conditionSpec)
+		}
+
+		condition := filterCondition{operator: "=", value: conditionSpec[equals+1:]}
+		fieldEnd := equals
+		switch conditionSpec[equals-1] {
+		case '!', '~':
+			condition.operator = conditionSpec[equals-1 : equals+1]
+			fieldEnd = equals - 1
+		}
+		condition.field = strings.TrimSpace(conditionSpec[:fieldEnd])
+
+		path, err := parseFieldPath(condition.field)
+		if err != nil {
+			return nil, err
+		}
+		condition.path = path
+
+		if condition.operator == "~=" {
+			condition.regexp, err = regexp.Compile(condition.value)
+			if err != nil {
+				return nil, fmt.Errorf("Error parsing filter '%s': %s", conditionSpec, err)
+			}
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// Parses a field name or JSONPath expression; name is the same as .name
+func parseFieldPath(field string) ([]jsonPathElement, error) {
+	if len(field) == 0 {
+		return nil, fmt.Errorf("Missing field name")
+	}
+	if !strings.HasPrefix(field, ".") && !strings.HasPrefix(field, "[") {
+		field = "." + field
+	}
+	return parseJsonPathExpression(field)
+}
+
+// Returns the values of the field as strings, expanding lists so that each element
+// can be matched on its own. A missing field has the empty string as its only value.
+func fieldValues(path []jsonPathElement, data interface{}) ([]string, error) {
+	values := []string{}
+	for _, value := range evalJsonPath(path, data) {
+		items := []interface{}{value}
+		if list, ok := value.([]interface{}); ok {
+			items = list
+		}
+		for _, item := range items {
+			text, err := jsonValueToString(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, text)
+		}
+	}
+	if len(values) == 0 {
+		values = append(values, "")
+	}
+	return values, nil
+}
+
+// Tells if the object matches the condition
+func (condition filterCondition) matches(data interface{}) (bool, error) {
+	values, err := fieldValues(condition.path, data)
+	if err != nil {
+		return false, err
+	}
+
+	for _, value := range values {
+		switch condition.operator {
+		case "=":
+			if value == condition.value {
+				return true, nil
+			}
+		case "!=":
+			if value == condition.value {
+				return false, nil
+			}
+		case "~=":
+			if condition.regexp.MatchString(value) {
+				return true, nil
+			}
+		}
+	}
+	return condition.operator == "!=", nil
+}
+
+// Validates the filter and sort expressions, so that errors can be reported before
+// calling the API
+func ValidateFilterAndSort(filter string, sortBy string) error {
+	_, err := parseFilter(filter)
+	if err != nil {
+		return err
+	}
+	if len(sortBy) != 0 {
+		_, err = parseFieldPath(sortBy)
+	}
+	return err
+}
+
+// Filters and sorts the list pointed to by listPtr (e.g. *[]photon.VM) in place.
+// An empty filter keeps all the objects and an empty sortBy keeps the server's order.
+func FilterAndSortList(listPtr interface{}, filter string, sortBy string) error {
+	conditions, err := parseFilter(filter)
+	if err != nil {
+		return err
+	}
+	var sortPath []jsonPathElement
+	if len(sortBy) != 0 {
+		sortPath, err = parseFieldPath(sortBy)
+		if err != nil {
+			return err
+		}
+	}
+	if len(conditions) == 0 && sortPath == nil {
+		return nil
+	}
+
+	list := reflect.ValueOf(listPtr).Elem()
+	kept := reflect.MakeSlice(list.Type(), 0, list.Len())
+	sortKeys := []interface{}{}
+	for i := 0; i < list.Len(); i++ {
+		data, err := toJsonValue(list.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+
+		matches := true
+		for _, condition := range conditions {
+			matches, err = condition.matches(data)
+			if err != nil {
+				return err
+			}
+			if !matches {
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+
+		kept = reflect.Append(kept, list.Index(i))
+		if sortPath != nil {
+			var key interface{}
+			values := evalJsonPath(sortPath, data)
+			if len(values) != 0 {
+				key = values[0]
+			}
+			sortKeys = append(sortKeys, key)
+		}
+	}
+
+	if sortPath != nil {
+		sort.Stable(&listSorter{kept, sortKeys})
+	}
+	list.Set(kept)
+	return nil
+}
+
+// Sorts a reflected slice using the sort key of each element
+type listSorter struct {
+	list reflect.Value
+	keys []interface{}
+}
+
+func (s *listSorter) Len() int { return s.list.Len() }
+
+func (s *listSorter) Swap(i, j int) {
+	a, b := s.list.Index(i).Interface(), s.list.Index(j).Interface()
+	s.list.Index(i).Set(reflect.ValueOf(b))
+	s.list.Index(j).Set(reflect.ValueOf(a))
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+func (s *listSorter) Less(i, j int) bool {
+	a, aIsNumber := s.keys[i].(json.Number)
+	b, bIsNumber := s.keys[j].(json.Number)
+	if aIsNumber && bIsNumber {
+		aValue, aErr := strconv.ParseFloat(a.String(), 64)
+		bValue, bErr := strconv.ParseFloat(b.String(), 64)
+		if aErr == nil && bErr == nil {
+			return aValue < bValue
+		}
+	}
+	aText, _ := jsonValueToString(s.keys[i])
+	bText, _ := jsonValueToString(s.keys[j])
+	return aText < bText
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/vmware/photon-controller-go-sdk/photon"
+)
+
+func vmNames(vms []photon.VM) []string {
+	names := []string{}
+	for _, vm := range vms {
+		names = append(names, vm.Name)
+	}
+	return names
+}
+
+func TestFilterAndSortList(t *testing.T) {
+	vms := []photon.VM{
+		{Name: "vm-c", State: "STARTED", Flavor: "core-large"},
+		{Name: "vm-a", State: "STOPPED", Flavor: "core-large"},
+		{Name: "vm-b", State: "STARTED", Flavor: "core-small"},
+		{Name: "vm-d", State: "STARTED", Flavor: "core-large-2"},
+	}
+
+	testCases := []struct {
+		filter   string
+		sortBy   string
+		expected []string
+	}{
+		{"", "", []string{"vm-c", "vm-a", "vm-b", "vm-d"}},
+		{"state=STARTED,flavor~=large", "", []string{"vm-c", "vm-d"}},
+		{".state!=STARTED", "", []string{"vm-a"}},
+		{"flavor~=^core-large$", "", []string{"vm-c", "vm-a"}},
+		{"host=", ".name", []string{"vm-a", "vm-b", "vm-c", "vm-d"}},
+		{"state=STARTED", "name", []string{"vm-b", "vm-c", "vm-d"}},
+		{"name=unknown", ".name", []string{}},
+	}
+
+	for _, testCase := range testCases {
+		list := append([]photon.VM{}, vms...)
+		err := FilterAndSortList(&list, testCase.filter, testCase.sortBy)
+		if err != nil {
+			t.Errorf("Not expecting error with filter '%s': %s", testCase.filter, err)
+			continue
+		}
+		names := vmNames(list)
+		if len(names) != len(testCase.expected) {
+			t.Errorf("Filter '%s', sort '%s': expected %v, got %v", testCase.filter, testCase.sortBy, testCase.expected, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != testCase.expected[i] {
+				t.Errorf("Filter '%s', sort '%s': expected %v, got %v", testCase.filter, testCase.sortBy, testCase.expected, names)
+				break
+			}
+		}
+	}
+}
+
+func TestFilterListField(t *testing.T) {
+	hosts := []photon.Host{
+		{ID: "host-1", Tags: []string{"CLOUD", "MGMT"}},
+		{ID: "host-2", Tags: []string{"CLOUD"}},
+		{ID: "host-3", Tags: []string{"MGMT"}},
+	}
+
+	err := FilterAndSortList(&hosts, "usageTags=MGMT", "")
+	if err != nil {
+		t.Error(err)
+	}
+	if len(hosts) != 2 || hosts[0].ID != "host-1" || hosts[1].ID != "host-3" {
+		t.Errorf("Unexpected hosts after filtering on tags: %v", hosts)
+	}
+}
+
+func TestSortListNumerically(t *testing.T) {
+	tasks := []photon.Task{
+		{ID: "task-1", StartedTime: 1000},
+		{ID: "task-2", StartedTime: 200},
+		{ID: "task-3", StartedTime: 30},
+	}
+
+	err := FilterAndSortList(&tasks, "", ".startedTime")
+	if err != nil {
+		t.Error(err)
+	}
+	if tasks[0].ID != "task-3" || tasks[1].ID != "task-2" || tasks[2].ID != "task-1" {
+		t.Errorf("Tasks not sorted numerically by start time: %v", tasks)
+	}
+}
+
+func TestInvalidFilter(t *testing.T) {
+	for _, filter := range []string{"state", "=STARTED", "name~=[", "state=STARTED,"} {
+		err := ValidateFilterAndSort(filter, "")
+		if err == nil {
+			t.Errorf("Expected error for filter '%s'", filter)
+		}
+	}
+	err := ValidateFilterAndSort("", ".tags[")
+	if err == nil {
+		t.Error("Expected error for invalid sort field")
+	}
+}
+
+func TestFilterNestedField(t *testing.T) {
+	tasks := []photon.Task{
+		{ID: "task-1", Operation: "CREATE_VM", Entity: photon.Entity{Kind: "vm"}},
+		{ID: "task-2", Operation: "CREATE_DISK", Entity: photon.Entity{Kind: "persistent-disk"}},
+		{ID: "task-3", Operation: "ATTACH_DISK", Entity: photon.Entity{Kind: "vm"}},
+	}
+	err := FilterAndSortList(&tasks, "operation~=_VM$,entity.kind=vm", "")
+	if err != nil {
+		t.Fatal("Not expecting error filtering tasks: " + err.Error())
+	}
+	if len(tasks) != 1 || tasks[0].ID != "task-1" {
+		t.Errorf("Expected only the VM task, got %v", tasks)
+	}
+}
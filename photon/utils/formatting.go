@@ -13,10 +13,16 @@ package utils
  * These utilities format output in a variety of ways.
  *
  * The goal is to have multiple methods of output so that it's easy to script the CLI
- * in whatever way a user wants. Currently we just implement JSON output, but we plan
- * to implement a subset of the JSONPath spec so that we can implement:
+ * in whatever way a user wants. Currently we implement JSON and YAML output, and a
+ * subset of the JSONPath spec (see jsonpath.go) so that we can:
  * - output just a single value (e.g. ID) from an object
- * - output a list of objects as a table with the columns specified by the user
+ * - output selected values from a list of objects, one line per object
+ * - output a list of objects as a table with the columns specified by the user (see columns.go)
+ * Users who need more control can also supply a Go template (see template.go), and lists
+ * can be exported as CSV or TSV for spreadsheets (see csv.go).
+ *
+ * Output types that take an argument are written as <type>=<argument>, for example
+ * --output jsonpath={.id}
  *
  * In order to make life easier for callers, they pass us the CLI context and we examine
  * the arguments in here. Note that the arguments are global arguments (they occur before
@@ -29,21 +35,57 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strings"
 
 	"github.com/codegangsta/cli"
+	"gopkg.in/yaml.v2"
 )
 
 // Called by main to validate the output arguments
-// Currently it only validates the --output argument. Eventually when we support
-// json path, it will validate that as well.
+// It validates the --output argument, including parsing the JSONPath template, the
+// custom columns or the Go template if there are any, and the --no-headers argument.
 func ValidateArgs(c *cli.Context) error {
 	if c.GlobalBool("non-interactive") == true && c.GlobalString("output") != "" {
 		return fmt.Errorf("--non-interactive and --output are mutually exclusive")
 	}
-	if c.GlobalString("output") != "" && c.GlobalString("output") != "json" {
-		return fmt.Errorf("output type must be 'json'")
+	outputType, argument := parseOutputType(c.GlobalString("output"))
+	if c.GlobalBool("no-headers") && outputType != "custom-columns" && outputType != "csv" && outputType != "tsv" {
+		return fmt.Errorf("--no-headers can only be used with custom-columns, csv or tsv output")
+	}
+	switch outputType {
+	case "", "json", "yaml", "csv", "tsv":
+		return nil
+	case "jsonpath":
+		if argument == "" {
+			return fmt.Errorf("jsonpath output requires a template, e.g. --output jsonpath={.id}")
+		}
+		_, err := parseJsonPath(argument)
+		return err
+	case "custom-columns":
+		if argument == "" {
+			return fmt.Errorf("custom-columns output requires columns, e.g. --output custom-columns=ID:.id,NAME:.name")
+		}
+		_, err := parseCustomColumns(argument)
+		return err
+	case "template", "template-file":
+		if argument == "" {
+			return fmt.Errorf("%s output requires an argument, e.g. --output template={{.ID}}", outputType)
+		}
+		_, err := parseOutputTemplate(outputType, argument)
+		return err
+	default:
+		return fmt.Errorf("output type must be 'json', 'yaml', 'csv', 'tsv', 'jsonpath=<template>', " +
+			"'custom-columns=<columns>', 'template=<template>' or 'template-file=<path>'")
+	}
+}
+
+// Splits an --output value of the form <type>=<argument> into its type and argument
+func parseOutputType(output string) (outputType string, argument string) {
+	parts := strings.SplitN(output, "=", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
 	}
-	return nil
+	return output, ""
 }
 
 // Tells the caller if we should assume the user wants non-interactive mode
@@ -63,12 +105,24 @@ func NeedsFormatting(c *cli.Context) bool {
 }
 
 // Outputs the given object (image, list of images, VM, etc...) as specified by the user
-// Currently we only support JSON output, but we will support more formats later.
+// Currently we support JSON, YAML, CSV, TSV, JSONPath, custom columns and template output.
 func FormatObject(o interface{}, w io.Writer, c *cli.Context) {
-	outputType := c.GlobalString("output")
+	outputType, argument := parseOutputType(c.GlobalString("output"))
 	switch outputType {
 	case "json":
 		formatObjectJson(o, w)
+	case "yaml":
+		formatObjectYaml(o, w)
+	case "jsonpath":
+		formatObjectJsonPath(o, argument, w)
+	case "custom-columns":
+		formatObjectCustomColumns(o, argument, c.GlobalBool("no-headers"), w)
+	case "template", "template-file":
+		formatObjectTemplate(o, outputType, argument, w)
+	case "csv":
+		formatObjectDelimited(o, ',', c.GlobalBool("no-headers"), w)
+	case "tsv":
+		formatObjectDelimited(o, '\t', c.GlobalBool("no-headers"), w)
 	default:
 		fmt.Fprintf(w, "Unknown output type: '%s'", outputType)
 	}
@@ -81,7 +135,7 @@ func FormatObjects(o interface{}, w io.Writer, c *cli.Context) {
 	value := reflect.ValueOf(o)
 	kind := value.Kind()
 	if (kind == reflect.Array || kind == reflect.Slice) && value.Len() == 0 {
-		FormatObject(new([0]int), w, c)
+		FormatObject([]interface{}{}, w, c)
 	} else {
 		FormatObject(o, w, c)
 	}
@@ -103,3 +157,83 @@ func formatObjectJson(o interface{}, w io.Writer) {
 	}
 	fmt.Fprintf(w, "%s\n", string(prettyJSON.Bytes()))
 }
+
+// Output an object as YAML
+// The object is first converted to JSON so that the keys match the json tags of the
+// SDK types and appear in the same order as they do in the JSON output.
+func formatObjectYaml(o interface{}, w io.Writer) {
+	ordered, err := toOrderedJsonValue(o)
+	if err != nil {
+		fmt.Fprintf(w, "Cannot convert output to YAML: %s", err)
+		return
+	}
+
+	yamlBytes, err := yaml.Marshal(ordered)
+	if err != nil {
+		fmt.Fprintf(w, "Cannot convert output to YAML: %s", err)
+		return
+	}
+	fmt.Fprintf(w, "%s", string(yamlBytes))
+}
+
+// Converts the given object to its generic JSON representation, like toJsonValue,
+// but keeps the keys of JSON objects in order
+func toOrderedJsonValue(o interface{}) (interface{}, error) {
+	jsonBytes, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+	return decodeOrderedJson(decoder)
+}
+
+// Decodes the next JSON value from the decoder, keeping JSON objects as yaml.MapSlice
+// so that the order of their keys is preserved when they are marshalled to YAML.
+func decodeOrderedJson(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		switch value {
+		case '{':
+			object := yaml.MapSlice{}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				item, err := decodeOrderedJson(decoder)
+				if err != nil {
+					return nil, err
+				}
+				object = append(object, yaml.MapItem{Key: key, Value: item})
+			}
+			_, err = decoder.Token()
+			return object, err
+		case '[':
+			list := []interface{}{}
+			for decoder.More() {
+				item, err := decodeOrderedJson(decoder)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, item)
+			}
+			_, err = decoder.Token()
+			return list, err
+		}
+		return nil, fmt.Errorf("unexpected delimiter '%s'", value)
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i, nil
+		}
+		return value.Float64()
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package utils
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/codegangsta/cli"
+	"github.com/vmware/photon-controller-go-sdk/photon"
+)
+
+// Creates a context with the given --output value set as a global flag
+func newOutputContext(t *testing.T, output string) *cli.Context {
+	globalFlags := flag.NewFlagSet("global-flags", flag.ContinueOnError)
+	globalFlags.String("output", "", "output")
+	err := globalFlags.Parse([]string{"--output=" + output})
+	if err != nil {
+		t.Error(err)
+	}
+	globalCtx := cli.NewContext(nil, globalFlags, nil)
+	return cli.NewContext(nil, flag.NewFlagSet("command-flags", flag.ContinueOnError), globalCtx)
+}
+
+func TestValidateArgs(t *testing.T) {
+	for _, output := range []string{"json", "yaml"} {
+		err := ValidateArgs(newOutputContext(t, output))
+		if err != nil {
+			t.Errorf("Not expecting error validating output '%s': %s", output, err)
+		}
+	}
+
+	err := ValidateArgs(newOutputContext(t, "xml"))
+	if err == nil {
+		t.Error("Expected error validating unknown output type")
+	}
+}
+
+func TestFormatObjectYaml(t *testing.T) {
+	vm := photon.VM{
+		ID:     "fake-vm-id",
+		Name:   "fake-vm-name",
+		State:  "STARTED",
+		Flavor: "fake-flavor",
+		Cost:   []photon.QuotaLineItem{{Key: "vm.cpu", Value: 1, Unit: "COUNT"}},
+	}
+
+	var output bytes.Buffer
+	FormatObject(vm, &output, newOutputContext(t, "yaml"))
+
+	expected := `cost:
+- unit: COUNT
+  value: 1
+  key: vm.cpu
+kind: ""
+attachedDisks: null
+flavor: fake-flavor
+name: fake-vm-name
+state: STARTED
+id: fake-vm-id
+`
+	if output.String() != expected {
+		t.Errorf("Unexpected YAML output, expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestFormatObjectsYamlEmptyList(t *testing.T) {
+	var output bytes.Buffer
+	FormatObjects([]photon.VM{}, &output, newOutputContext(t, "yaml"))
+
+	if output.String() != "[]\n" {
+		t.Errorf("Expected empty YAML list, got: %s", output.String())
+	}
+}
@@ -0,0 +1,337 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package utils
+
+/**
+ * A small subset of JSONPath, in the style of kubectl's jsonpath output.
+ *
+ * A template is plain text with expressions in curly braces, e.g.
+ *   {.id}
+ *   {range .[*]}{.name}{"\t"}{.state}{"\n"}{end}
+ *
+ * Supported expressions:
+ * - paths made of fields (.name), array indexes ([0], [-1]) and wildcards ([*], .*)
+ *   optionally starting with $ (the whole object) or @ (the current object)
+ * - quoted string literals such as {"\t"}, to output characters that are hard to type
+ * - {range <path>} ... {end}, to repeat part of the template for each matching value
+ *
+ * Paths are evaluated against the JSON representation of the object, so field names
+ * are the ones used in the JSON output. A path that matches nothing outputs nothing.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type jsonPathNode interface{}
+
+// Literal text in the template
+type jsonPathText struct {
+	text string
+}
+
+// A path to evaluate, whose values are printed
+type jsonPathField struct {
+	path []jsonPathElement
+}
+
+// A {range <path>}...{end} block
+type jsonPathRange struct {
+	path []jsonPathElement
+	body []jsonPathNode
+}
+
+// One step in a path: a field name, an array index or a wildcard
+type jsonPathElement struct {
+	name     string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Parses a JSONPath template into a list of nodes
+func parseJsonPath(template string) ([]jsonPathNode, error) {
+	nodes, _, err := parseJsonPathNodes(template, false)
+	return nodes, err
+}
+
+// Parses nodes until the end of the template or, if inRange is true, until a matching {end}.
+// Returns the nodes and the remainder of the template after the {end}.
+func parseJsonPathNodes(template string, inRange bool) ([]jsonPathNode, string, error) {
+	nodes := []jsonPathNode{}
+	for len(template) != 0 {
+		start := strings.Index(template, "{")
+		if start == -1 {
+			nodes = append(nodes, jsonPathText{template})
+			template = ""
+			break
+		}
+		if start > 0 {
+			nodes = append(nodes, jsonPathText{template[:start]})
+		}
+
+		end := findJsonPathExpressionEnd(template, start)
+		if end == -1 {
+			return nil, "", fmt.Errorf("unclosed '{' in JSONPath template")
+		}
+		expression := strings.TrimSpace(template[start+1 : end])
+		template = template[end+1:]
+
+		switch {
+		case expression == "end":
+			if !inRange {
+				return nil, "", fmt.Errorf("unexpected {end} in JSONPath template")
+			}
+			return nodes, template, nil
+		case strings.HasPrefix(expression, "range "):
+			path, err := parseJsonPathExpression(strings.TrimSpace(strings.TrimPrefix(expression, "range ")))
+			if err != nil {
+				return nil, "", err
+			}
+			body, rest, err := parseJsonPathNodes(template, true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathRange{path: path, body: body})
+			template = rest
+		case strings.HasPrefix(expression, "\""):
+			text, err := strconv.Unquote(expression)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid string literal %s in JSONPath template", expression)
+			}
+			nodes = append(nodes, jsonPathText{text})
+		default:
+			path, err := parseJsonPathExpression(expression)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathField{path})
+		}
+	}
+
+	if inRange {
+		return nil, "", fmt.Errorf("missing {end} for {range} in JSONPath template")
+	}
+	return nodes, template, nil
+}
+
+// Finds the closing brace of the expression starting at start, skipping over quoted strings
+func findJsonPathExpressionEnd(template string, start int) int {
+	inQuote := false
+	for i := start + 1; i < len(template); i++ {
+		switch template[i] {
+		case '\\':
+			if inQuote {
+				i++
+			}
+		case '"':
+			inQuote = !inQuote
+		case '}':
+			if !inQuote {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Parses a path such as .items[0].name into its elements
+func parseJsonPathExpression(expression string) ([]jsonPathElement, error) {
+	path := []jsonPathElement{}
+	rest := expression
+	if strings.HasPrefix(rest, "$") || strings.HasPrefix(rest, "@") {
+		rest = rest[1:]
+	}
+	if len(rest) != 0 && rest[0] != '.' && rest[0] != '[' {
+		return nil, fmt.Errorf("invalid JSONPath expression '%s': must start with '.'", expression)
+	}
+
+	for len(rest) != 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			rest = rest[end:]
+			if name == "*" {
+				path = append(path, jsonPathElement{wildcard: true})
+			} else if len(name) != 0 {
+				path = append(path, jsonPathElement{name: name})
+			}
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("invalid JSONPath expression '%s': unclosed '['", expression)
+			}
+			subscript := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if subscript == "*" {
+				path = append(path, jsonPathElement{wildcard: true})
+				continue
+			}
+			index, err := strconv.Atoi(subscript)
+			if err != nil {
+				name, err := strconv.Unquote(strings.Replace(subscript, "'", "\"", -1))
+				if err != nil {
+					return nil, fmt.Errorf("invalid JSONPath expression '%s': bad subscript '%s'", expression, subscript)
+				}
+				path = append(path, jsonPathElement{name: name})
+				continue
+			}
+			path = append(path, jsonPathElement{index: index, isIndex: true})
+		default:
+			return nil, fmt.Errorf("invalid JSONPath expression '%s'", expression)
+		}
+	}
+	return path, nil
+}
+
+// Finds all the values matching the path, starting at data
+func evalJsonPath(path []jsonPathElement, data interface{}) []interface{} {
+	values := []interface{}{data}
+	for _, element := range path {
+		next := []interface{}{}
+		for _, value := range values {
+			switch typed := value.(type) {
+			case map[string]interface{}:
+				if element.wildcard {
+					for _, key := range sortedKeys(typed) {
+						next = append(next, typed[key])
+					}
+				} else if item, ok := typed[element.name]; ok && !element.isIndex {
+					next = append(next, item)
+				}
+			case []interface{}:
+				if element.wildcard {
+					next = append(next, typed...)
+				} else if element.isIndex {
+					index := element.index
+					if index < 0 {
+						index += len(typed)
+					}
+					if index >= 0 && index < len(typed) {
+						next = append(next, typed[index])
+					}
+				}
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// Returns the keys of the map in sorted order, so that wildcards give stable output
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Converts the given object to its generic JSON representation: maps, slices and scalars
+func toJsonValue(o interface{}) (interface{}, error) {
+	jsonBytes, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+	err = decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Writes the result of the template executed against data
+func executeJsonPath(nodes []jsonPathNode, data interface{}, w io.Writer) error {
+	for _, node := range nodes {
+		switch typed := node.(type) {
+		case jsonPathText:
+			fmt.Fprint(w, typed.text)
+		case jsonPathField:
+			values := evalJsonPath(typed.path, data)
+			for i, value := range values {
+				if i > 0 {
+					fmt.Fprint(w, " ")
+				}
+				text, err := jsonValueToString(value)
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(w, text)
+			}
+		case jsonPathRange:
+			for _, value := range evalJsonPath(typed.path, data) {
+				err := executeJsonPath(typed.body, value, w)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Converts a single value to text: scalars are printed as-is, objects and lists as JSON
+func jsonValueToString(value interface{}) (string, error) {
+	switch typed := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return typed, nil
+	case json.Number:
+		return typed.String(), nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	default:
+		jsonBytes, err := json.Marshal(typed)
+		if err != nil {
+			return "", err
+		}
+		return string(jsonBytes), nil
+	}
+}
+
+// Output an object using a JSONPath template
+func formatObjectJsonPath(o interface{}, template string, w io.Writer) {
+	nodes, err := parseJsonPath(template)
+	if err != nil {
+		fmt.Fprintf(w, "Cannot parse JSONPath template: %s", err)
+		return
+	}
+
+	data, err := toJsonValue(o)
+	if err != nil {
+		fmt.Fprintf(w, "Cannot convert output to JSON: %s", err)
+		return
+	}
+
+	var buffer bytes.Buffer
+	err = executeJsonPath(nodes, data, &buffer)
+	if err != nil {
+		fmt.Fprintf(w, "Cannot format output with JSONPath: %s", err)
+		return
+	}
+	fmt.Fprint(w, buffer.String())
+}
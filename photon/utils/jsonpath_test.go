@@ -0,0 +1,100 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vmware/photon-controller-go-sdk/photon"
+)
+
+var jsonPathTestVMs = []photon.VM{
+	{
+		ID:     "vm-1",
+		Name:   "first",
+		State:  "STARTED",
+		Flavor: "small",
+		AttachedDisks: []photon.AttachedDisk{
+			{Name: "boot", CapacityGB: 10, BootDisk: true},
+		},
+		Metadata: map[string]string{"owner": "alice"},
+	},
+	{
+		ID:     "vm-2",
+		Name:   "second",
+		State:  "STOPPED",
+		Flavor: "large",
+	},
+}
+
+func TestFormatObjectJsonPath(t *testing.T) {
+	testCases := []struct {
+		object   interface{}
+		template string
+		expected string
+	}{
+		{jsonPathTestVMs[0], "{.id}", "vm-1"},
+		{jsonPathTestVMs[0], "{$.name}", "first"},
+		{jsonPathTestVMs[0], "name={.name} state={.state}", "name=first state=STARTED"},
+		{jsonPathTestVMs[0], "{.attachedDisks[0].capacityGb}", "10"},
+		{jsonPathTestVMs[0], "{.attachedDisks[*].bootDisk}", "true"},
+		{jsonPathTestVMs[0], "{.metadata['owner']}", "alice"},
+		{jsonPathTestVMs[0], "{.metadata}", `{"owner":"alice"}`},
+		{jsonPathTestVMs[0], "{.host}", ""},
+		{jsonPathTestVMs, "{.[*].id}", "vm-1 vm-2"},
+		{jsonPathTestVMs, "{[-1].name}", "second"},
+		{jsonPathTestVMs, `{range .[*]}{.name}{"\t"}{.state}{"\n"}{end}`, "first\tSTARTED\nsecond\tSTOPPED\n"},
+		{jsonPathTestVMs, `{range .[*]}{range .attachedDisks[*]}{@.name}{end}{end}`, "boot"},
+	}
+
+	for _, testCase := range testCases {
+		var output bytes.Buffer
+		FormatObject(testCase.object, &output, newOutputContext(t, "jsonpath="+testCase.template))
+		if output.String() != testCase.expected {
+			t.Errorf("JSONPath '%s': expected '%s', got '%s'", testCase.template, testCase.expected, output.String())
+		}
+	}
+}
+
+func TestParseJsonPathErrors(t *testing.T) {
+	templates := []string{
+		"{.id",
+		"{range .[*]}{.id}",
+		"{end}",
+		"{id}",
+		"{.items[}",
+		`{"unterminated}`,
+	}
+
+	for _, template := range templates {
+		_, err := parseJsonPath(template)
+		if err == nil {
+			t.Errorf("Expected error parsing JSONPath template '%s'", template)
+		}
+	}
+}
+
+func TestValidateArgsJsonPath(t *testing.T) {
+	err := ValidateArgs(newOutputContext(t, "jsonpath={.id}"))
+	if err != nil {
+		t.Errorf("Not expecting error validating a JSONPath template: %s", err)
+	}
+
+	err = ValidateArgs(newOutputContext(t, "jsonpath="))
+	if err == nil {
+		t.Error("Expected error validating an empty JSONPath template")
+	}
+
+	err = ValidateArgs(newOutputContext(t, "jsonpath={range .[*]}"))
+	if err == nil {
+		t.Error("Expected error validating a JSONPath template without {end}")
+	}
+}
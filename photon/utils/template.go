@@ -0,0 +1,100 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package utils
+
+/**
+ * Template output executes a Go text/template against the object returned by the command:
+ *   --output template='{{range .}}{{.Name}} {{.State}}{{"\n"}}{{end}}'
+ *   --output template-file=inventory.tmpl
+ *
+ * Unlike the other formats, the template sees the SDK object itself, so fields are
+ * referred to by their Go names (e.g. .AttachedDisks rather than .attachedDisks).
+ * In addition to the standard template functions, templates can use:
+ * - join <list> <separator>: joins the elements of a list into a string
+ * - timestamp <milliseconds>: formats a task timestamp, like the rest of the CLI
+ * - json <value>: converts a value to JSON
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var templateFuncs = template.FuncMap{
+	"join":      joinTemplateList,
+	"timestamp": TimestampToString,
+	"json":      toJsonString,
+}
+
+// Converts milliseconds since the epoch (as used in tasks) to a string
+// If the time is zero or negative, returns the string "-"
+// We do this because a timestamp of zero in a task means "undefined"
+func TimestampToString(timestamp int64) string {
+	if timestamp <= 0 {
+		return "-"
+	} else {
+		return time.Unix(timestamp/1000, 0).Format("2006-01-02 03:04:05.00")
+	}
+}
+
+// Joins the elements of any list or array into a string
+func joinTemplateList(list interface{}, separator string) (string, error) {
+	value := reflect.ValueOf(list)
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return "", fmt.Errorf("join expects a list, not %s", value.Kind())
+	}
+	items := []string{}
+	for i := 0; i < value.Len(); i++ {
+		items = append(items, fmt.Sprint(value.Index(i).Interface()))
+	}
+	return strings.Join(items, separator), nil
+}
+
+// Converts a value to compact JSON
+func toJsonString(o interface{}) (string, error) {
+	jsonBytes, err := json.Marshal(o)
+	if err != nil {
+		return "", err
+	}
+	return string(jsonBytes), nil
+}
+
+// Parses the template given with template=<text> or template-file=<path>
+func parseOutputTemplate(outputType string, argument string) (*template.Template, error) {
+	text := argument
+	if outputType == "template-file" {
+		data, err := ioutil.ReadFile(argument)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read template file: %s", err)
+		}
+		text = string(data)
+	}
+	return template.New("output").Funcs(templateFuncs).Parse(text)
+}
+
+// Output an object using a Go template
+func formatObjectTemplate(o interface{}, outputType string, argument string, w io.Writer) {
+	tmpl, err := parseOutputTemplate(outputType, argument)
+	if err != nil {
+		fmt.Fprintf(w, "Cannot parse template: %s", err)
+		return
+	}
+
+	err = tmpl.Execute(w, o)
+	if err != nil {
+		fmt.Fprintf(w, "Cannot format output with template: %s", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2016 VMware, Inc. All Rights Reserved.
+//
+// This product is licensed to you under the Apache License, Version 2.0 (the "License").
+// You may not use this product except in compliance with the License.
+//
+// This product may include a number of subcomponents with separate copyright notices and
+// license terms. Your use of these subcomponents is subject to the terms and conditions
+// of the subcomponent's license, as noted in the LICENSE file.
+
+package utils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/vmware/photon-controller-go-sdk/photon"
+)
+
+func TestFormatObjectsTemplate(t *testing.T) {
+	hosts := []photon.Host{
+		{ID: "host-1", Address: "10.0.0.1", Tags: []string{"CLOUD", "MGMT"}},
+		{ID: "host-2", Address: "10.0.0.2", Tags: []string{"CLOUD"}},
+	}
+
+	var output bytes.Buffer
+	template := `{{range .}}{{.Address}} tags={{join .Tags ","}}{{"\n"}}{{end}}`
+	FormatObjects(hosts, &output, newOutputContext(t, "template="+template))
+
+	expected := "10.0.0.1 tags=CLOUD,MGMT\n10.0.0.2 tags=CLOUD\n"
+	if output.String() != expected {
+		t.Errorf("Unexpected template output, expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+
+	output.Reset()
+	FormatObjects([]photon.Host{}, &output, newOutputContext(t, "template={{len .}}"))
+	if output.String() != "0" {
+		t.Errorf("Unexpected template output for an empty list: '%s'", output.String())
+	}
+}
+
+func TestFormatObjectTemplateFuncs(t *testing.T) {
+	task := photon.Task{
+		ID:          "task-1",
+		StartedTime: 0,
+		Entity:      photon.Entity{ID: "vm-1", Kind: "vm"},
+	}
+
+	var output bytes.Buffer
+	FormatObject(task, &output, newOutputContext(t, "template={{.ID}} {{timestamp .StartedTime}} {{json .Entity}}"))
+
+	expected := `task-1 - {"id":"vm-1","kind":"vm"}`
+	if output.String() != expected {
+		t.Errorf("Unexpected template output, expected '%s', got '%s'", expected, output.String())
+	}
+}
+
+func TestFormatObjectTemplateFile(t *testing.T) {
+	file, err := ioutil.TempFile("", "template-test-")
+	if err != nil {
+		t.Error(err)
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("[vms]\n{{range .}}{{.Name}} flavor={{.Flavor}}\n{{end}}")
+	if err != nil {
+		t.Error(err)
+	}
+	err = file.Close()
+	if err != nil {
+		t.Error(err)
+	}
+
+	cxt := newOutputContext(t, "template-file="+file.Name())
+	err = ValidateArgs(cxt)
+	if err != nil {
+		t.Errorf("Not expecting error validating a template file: %s", err)
+	}
+
+	var output bytes.Buffer
+	FormatObjects([]photon.VM{{Name: "vm-1", Flavor: "small"}}, &output, cxt)
+	expected := "[vms]\nvm-1 flavor=small\n"
+	if output.String() != expected {
+		t.Errorf("Unexpected template file output, expected:\n%s\ngot:\n%s", expected, output.String())
+	}
+}
+
+func TestValidateArgsTemplate(t *testing.T) {
+	err := ValidateArgs(newOutputContext(t, "template={{.ID"))
+	if err == nil {
+		t.Error("Expected error validating an invalid template")
+	}
+
+	err = ValidateArgs(newOutputContext(t, "template-file=/non-existent/template"))
+	if err == nil {
+		t.Error("Expected error validating a missing template file")
+	}
+}